@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/alerts"
+	"btpTracker/backend/store"
+)
+
+const alertsRoute = "/api/v1/alerts"
+
+// Evaluate the alert rules on the rows stored by a scrape and deliver the
+// notifications of the rules triggered.
+func (s *server) evaluateAlerts(rows map[string][]store.Row, descriptions map[string]string, now time.Time) {
+	if s.alerts == nil {
+		// The rules are kept in MongoDB: without it there are none.
+		return
+	}
+	rules, err := s.alerts.ListAlertRules()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	quotes := map[string]alerts.Quote{}
+	for _, sourceRows := range rows {
+		for _, row := range sourceRows {
+			quotes[row.ISIN] = alerts.Quote{
+				ISIN:        row.ISIN,
+				Description: descriptions[row.ISIN],
+				Price:       row.Price,
+				YTM:         row.YTM,
+				Spread:      row.SpreadToCurve,
+			}
+		}
+	}
+
+	for _, n := range alerts.Evaluate(rules, quotes, now) {
+		if err := s.alerts.SetAlertTriggered(n.RuleID, now); err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		log.Printf("Alert %s triggered: %s %s %s %g (%g)\n", n.RuleID.Hex(), n.ISIN, n.Metric, n.Operator, n.Threshold, n.Value)
+		for _, notifier := range s.notifiers {
+			// Deliveries are retried for a while: do not hold the scrape.
+			s.deliveries.Add(1)
+			go s.deliverAlert(notifier, n)
+		}
+	}
+}
+
+// Deliver the notification and log the delivery.
+func (s *server) deliverAlert(notifier alerts.Notifier, n alerts.Notification) {
+	defer s.deliveries.Done()
+	delivery := alerts.Deliver(notifier, n, alerts.DefaultRetry)
+	if !delivery.Delivered {
+		log.Printf("Cannot deliver alert %s over %s: %s\n", n.RuleID.Hex(), delivery.Channel, delivery.Error)
+	}
+	if err := s.alerts.InsertAlertDelivery(&delivery); err != nil {
+		fmt.Println("Error:", err)
+	}
+}
+
+// Routes the alert endpoints:
+//   - /api/v1/alerts: GET, POST
+//   - /api/v1/alerts/{id}: GET, PUT, DELETE
+//   - /api/v1/alerts/{id}/deliveries: GET
+func (s *server) alertsHandler(w http.ResponseWriter, r *http.Request) {
+	// Enable CORS.
+	(w).Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, alertsRoute), "/")
+	if path == "" {
+		s.handleAlertRules(w, r)
+		return
+	}
+	parts := strings.Split(path, "/")
+	ids, err := parseIDs(parts[:1])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		s.handleAlertRule(w, r, ids[0])
+	case len(parts) == 2 && parts[1] == "deliveries":
+		s.getAlertDeliveries(w, r, ids[0])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *server) handleAlertRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		rules, err := s.alerts.ListAlertRules()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, rules)
+	case "POST":
+		var rule alerts.Rule
+		if err := readJSON(r, &rule); err != nil {
+			writeError(w, err)
+			return
+		}
+		if _, ok := s.findInstrument(w, rule.ISIN); !ok {
+			return
+		}
+		if err := s.alerts.CreateAlertRule(&rule); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeCreated(w, rule)
+	default:
+		http.Error(w, "Only GET and POST are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *server) handleAlertRule(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) {
+	switch r.Method {
+	case "GET":
+		rule, err := s.alerts.GetAlertRule(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, rule)
+	case "PUT":
+		var rule alerts.Rule
+		if err := readJSON(r, &rule); err != nil {
+			writeError(w, err)
+			return
+		}
+		if _, ok := s.findInstrument(w, rule.ISIN); !ok {
+			return
+		}
+		rule.ID = id
+		if err := s.alerts.UpdateAlertRule(rule); err != nil {
+			writeError(w, err)
+			return
+		}
+		updated, err := s.alerts.GetAlertRule(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, updated)
+	case "DELETE":
+		if err := s.alerts.DeleteAlertRule(id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Only GET, PUT and DELETE are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+// Serves the log of the deliveries of the notifications of the rule, most
+// recent first.
+func (s *server) getAlertDeliveries(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) {
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	deliveries, err := s.alerts.ListAlertDeliveries(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, deliveries)
+}
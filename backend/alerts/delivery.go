@@ -0,0 +1,57 @@
+package alerts
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notifier delivers notifications over a channel.
+type Notifier interface {
+	// Name of the channel, e.g. "webhook".
+	Channel() string
+	Notify(n Notification) error
+}
+
+// Retry policy of the deliveries: the delay doubles after each failed attempt.
+type Retry struct {
+	Attempts int
+	Delay    time.Duration
+}
+
+var DefaultRetry = Retry{Attempts: 4, Delay: 5 * time.Second}
+
+// Delivery of a notification over a channel, as stored in the
+// `alert_deliveries` collection.
+type Delivery struct {
+	ID           primitive.ObjectID `json:"ID" bson:"_id,omitempty"`
+	Channel      string             `json:"Channel" bson:"Channel"`
+	Notification Notification       `json:"Notification" bson:"Notification"`
+	Attempts     int                `json:"Attempts" bson:"Attempts"`
+	Delivered    bool               `json:"Delivered" bson:"Delivered"`
+	// Error of the last failed attempt.
+	Error string    `json:"Error,omitempty" bson:"Error,omitempty"`
+	Date  time.Time `json:"Date" bson:"Date"`
+}
+
+// Deliver sends the notification with the notifier, retrying on failure.
+func Deliver(notifier Notifier, n Notification, retry Retry) Delivery {
+	delivery := Delivery{Channel: notifier.Channel(), Notification: n}
+	delay := retry.Delay
+	for delivery.Attempts < max(retry.Attempts, 1) {
+		if delivery.Attempts > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		delivery.Attempts++
+		err := notifier.Notify(n)
+		if err == nil {
+			delivery.Delivered = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+	}
+	delivery.Date = time.Now()
+	return delivery
+}
@@ -0,0 +1,70 @@
+package alerts
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Notifier failing the first `failures` attempts.
+type flaky struct {
+	failures int
+	attempts []time.Time
+}
+
+func (f *flaky) Channel() string {
+	return "flaky"
+}
+
+func (f *flaky) Notify(n Notification) error {
+	f.attempts = append(f.attempts, time.Now())
+	if len(f.attempts) <= f.failures {
+		return fmt.Errorf("attempt %d failed", len(f.attempts))
+	}
+	return nil
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		attempts  int
+		delivered bool
+		err       string
+	}{
+		{"first attempt", 0, 1, true, ""},
+		{"after retries", 2, 3, true, ""},
+		{"gives up", 5, 4, false, "attempt 4 failed"},
+	}
+	retry := Retry{Attempts: 4, Delay: 5 * time.Millisecond}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &flaky{failures: tt.failures}
+			delivery := Deliver(notifier, Notification{ISIN: "IT0005240830"}, retry)
+			if delivery.Attempts != tt.attempts || len(notifier.attempts) != tt.attempts {
+				t.Errorf("%d attempts recorded, %d made, want %d", delivery.Attempts, len(notifier.attempts), tt.attempts)
+			}
+			if delivery.Delivered != tt.delivered || delivery.Error != tt.err {
+				t.Errorf("delivered %t with error %q, want %t and %q", delivery.Delivered, delivery.Error, tt.delivered, tt.err)
+			}
+			if delivery.Channel != "flaky" || delivery.Notification.ISIN != "IT0005240830" || delivery.Date.IsZero() {
+				t.Errorf("delivery %+v", delivery)
+			}
+			// The delay doubles after each failed attempt.
+			delay := retry.Delay
+			for i := 1; i < len(notifier.attempts); i++ {
+				if waited := notifier.attempts[i].Sub(notifier.attempts[i-1]); waited < delay {
+					t.Errorf("attempt %d after %s, want at least %s", i+1, waited, delay)
+				}
+				delay *= 2
+			}
+		})
+	}
+}
+
+func TestDeliverAttemptsOnceWithoutRetries(t *testing.T) {
+	notifier := &flaky{failures: 1}
+	if delivery := Deliver(notifier, Notification{}, Retry{}); delivery.Attempts != 1 || delivery.Delivered {
+		t.Errorf("delivery %+v, want a single failed attempt", delivery)
+	}
+}
@@ -0,0 +1,227 @@
+package alerts
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TLS modes of the connection to the SMTP server.
+const (
+	// Plain connection, e.g. to a local test server.
+	TLSNone = "none"
+	// Plain connection upgraded with STARTTLS, usually on port 587.
+	TLSStartTLS = "starttls"
+	// Implicit TLS, usually on port 465.
+	TLSImplicit = "tls"
+)
+
+// Time allowed to the SMTP session when the Email has no Timeout.
+const defaultEmailTimeout = 30 * time.Second
+
+// Email sends the notifications as multipart plain text and HTML messages.
+type Email struct {
+	Host string
+	Port int
+	// One of TLSNone, TLSStartTLS and TLSImplicit.
+	TLS string
+	// Credentials of the PLAIN authentication, skipped when Username is empty.
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Time allowed to the whole session, from the dial to the QUIT, so that a
+	// server that stops responding does not hold the delivery forever.
+	// Defaults to defaultEmailTimeout.
+	Timeout time.Duration
+}
+
+// EmailFromEnv reads the SMTP configuration from the environment:
+//   - SMTP_HOST and SMTP_PORT (default 587);
+//   - SMTP_TLS: "none", "starttls" (default) or "tls";
+//   - SMTP_USERNAME and SMTP_PASSWORD;
+//   - SMTP_FROM and SMTP_TO, a comma separated list of recipients.
+//
+// Returns nil if no host or no recipient is set.
+func EmailFromEnv() (*Email, error) {
+	host := os.Getenv("SMTP_HOST")
+	var to []string
+	for _, address := range strings.Split(os.Getenv("SMTP_TO"), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			to = append(to, address)
+		}
+	}
+	if host == "" || len(to) == 0 {
+		return nil, nil
+	}
+
+	e := &Email{
+		Host:     host,
+		Port:     587,
+		TLS:      TLSStartTLS,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+		To:       to,
+	}
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		parsed, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("SMTP_PORT: %w", err)
+		}
+		e.Port = parsed
+	}
+	if mode := os.Getenv("SMTP_TLS"); mode != "" {
+		switch mode {
+		case TLSNone, TLSStartTLS, TLSImplicit:
+			e.TLS = mode
+		default:
+			return nil, fmt.Errorf("SMTP_TLS must be one of %q, %q and %q", TLSNone, TLSStartTLS, TLSImplicit)
+		}
+	}
+	if e.From == "" {
+		e.From = "btptracker@" + host
+	}
+	return e, nil
+}
+
+var subjectTemplate = template.Must(template.New("subject").Parse(
+	`{{.ISIN}} {{.Metric}} {{.Operator}} {{.Threshold}}`))
+
+var textTemplate = template.Must(template.New("text").Parse(`The alert on {{.ISIN}}{{with .Description}} ({{.}}){{end}} has triggered.
+
+Rule:          {{.Metric}} {{.Operator}} {{.Threshold}}
+Value:         {{printf "%.4g" .Value}}
+Price:         {{printf "%.3f" .Price}}
+Triggered at:  {{.TriggeredAt.Format "02/01/2006 15:04"}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<html><body>
+<p>The alert on <b>{{.ISIN}}</b>{{with .Description}} ({{.}}){{end}} has triggered.</p>
+<table>
+<tr><td>Rule</td><td>{{.Metric}} {{.Operator}} {{.Threshold}}</td></tr>
+<tr><td>Value</td><td>{{printf "%.4g" .Value}}</td></tr>
+<tr><td>Price</td><td>{{printf "%.3f" .Price}}</td></tr>
+<tr><td>Triggered at</td><td>{{.TriggeredAt.Format "02/01/2006 15:04"}}</td></tr>
+</table>
+</body></html>
+`))
+
+// Message renders the notification as a MIME message with a plain text and an
+// HTML alternative.
+func (e *Email) Message(n Notification) ([]byte, error) {
+	var subject, text, html bytes.Buffer
+	if err := subjectTemplate.Execute(&subject, n); err != nil {
+		return nil, err
+	}
+	if err := textTemplate.Execute(&text, n); err != nil {
+		return nil, err
+	}
+	if err := htmlTemplate.Execute(&html, n); err != nil {
+		return nil, err
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	boundary := hex.EncodeToString(random)
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[btpTracker] "+subject.String()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", n.TriggeredAt.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", boundary)
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{{"text/plain", text.String()}, {"text/html", html.String()}} {
+		fmt.Fprintf(&msg, "--%s\r\n", boundary)
+		fmt.Fprintf(&msg, "Content-Type: %s; charset=utf-8\r\n\r\n", part.contentType)
+		msg.WriteString(strings.ReplaceAll(part.body, "\n", "\r\n"))
+		msg.WriteString("\r\n")
+	}
+	fmt.Fprintf(&msg, "--%s--\r\n", boundary)
+	return msg.Bytes(), nil
+}
+
+func (e *Email) Channel() string {
+	return "email"
+}
+
+func (e *Email) Notify(n Notification) error {
+	msg, err := e.Message(n)
+	if err != nil {
+		return err
+	}
+
+	timeout := e.Timeout
+	if timeout == 0 {
+		timeout = defaultEmailTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	address := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	var conn net.Conn
+	if e.TLS == TLSImplicit {
+		conn, err = tls.DialWithDialer(&net.Dialer{Deadline: deadline}, "tcp", address, &tls.Config{ServerName: e.Host})
+	} else {
+		conn, err = net.DialTimeout("tcp", address, timeout)
+	}
+	if err != nil {
+		return err
+	}
+	// The deadline holds after STARTTLS too, which wraps the connection.
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if e.TLS == TLSStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: e.Host}); err != nil {
+			return err
+		}
+	}
+	if e.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
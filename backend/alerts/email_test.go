@@ -0,0 +1,197 @@
+package alerts
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Envelope and message received by the SMTP stub.
+type received struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// Address of a MAIL FROM or RCPT TO command, e.g. "FROM:<a@b.c> BODY=8BITMIME".
+func envelopeAddress(arg string) string {
+	_, address, _ := strings.Cut(arg, "<")
+	address, _, _ = strings.Cut(address, ">")
+	return address
+}
+
+// Start an SMTP server on a local port that accepts a single message, without
+// TLS nor authentication. The message is sent on the channel once the session
+// is over.
+func smtpStub(t *testing.T) (*net.TCPAddr, <-chan received) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	done := make(chan received, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		session := textproto.NewConn(conn)
+		var msg received
+		reply := func(format string, args ...any) bool {
+			return session.PrintfLine(format, args...) == nil
+		}
+		if !reply("220 localhost ESMTP stub") {
+			return
+		}
+		for {
+			line, err := session.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				reply("250-localhost\r\n250 8BITMIME")
+			case "MAIL":
+				msg.from = envelopeAddress(arg)
+				reply("250 OK")
+			case "RCPT":
+				msg.to = append(msg.to, envelopeAddress(arg))
+				reply("250 OK")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				if msg.data, err = session.ReadDotBytes(); err != nil {
+					return
+				}
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				done <- msg
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr), done
+}
+
+func TestEmailNotify(t *testing.T) {
+	addr, done := smtpStub(t)
+	e := &Email{
+		Host: addr.IP.String(),
+		Port: addr.Port,
+		TLS:  TLSNone,
+		From: "btptracker@example.com",
+		To:   []string{"desk@example.com", "risk@example.com"},
+	}
+	n := Notification{
+		ISIN:        "IT0005240830",
+		Description: "Btp-1gn27 2,2%",
+		Metric:      Price,
+		Operator:    Below,
+		Threshold:   99,
+		Value:       98.95,
+		Price:       98.95,
+		TriggeredAt: time.Date(2026, time.October, 19, 10, 30, 0, 0, time.UTC),
+	}
+	if err := e.Notify(n); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+
+	var msg received
+	select {
+	case msg = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+	if msg.from != e.From || strings.Join(msg.to, ",") != "desk@example.com,risk@example.com" {
+		t.Errorf("envelope from %s to %v", msg.from, msg.to)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(msg.data)))
+	if err != nil {
+		t.Fatalf("cannot parse the message: %s", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[btpTracker] IT0005240830 price < 99"; subject != want {
+		t.Errorf("subject %q, want %q", subject, want)
+	}
+	if to := parsed.Header.Get("To"); to != "desk@example.com, risk@example.com" {
+		t.Errorf("To %q", to)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type %s, %v; want multipart/alternative", mediaType, err)
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, want := range []struct {
+		contentType string
+		contains    string
+	}{
+		{"text/plain", "Rule:          price < 99"},
+		{"text/html", "<b>IT0005240830</b> (Btp-1gn27 2,2%)"},
+	} {
+		part, err := parts.NextPart()
+		if err != nil {
+			t.Fatalf("missing %s part: %s", want.contentType, err)
+		}
+		if ct := part.Header.Get("Content-Type"); ct != want.contentType+"; charset=utf-8" {
+			t.Errorf("part of type %s, want %s", ct, want.contentType)
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), want.contains) {
+			t.Errorf("%s part does not contain %q:\n%s", want.contentType, want.contains, body)
+		}
+	}
+	if _, err := parts.NextPart(); err != io.EOF {
+		t.Errorf("more than two parts: %v", err)
+	}
+}
+
+func TestEmailNotifyTimesOut(t *testing.T) {
+	// The server accepts the connection and never greets.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	e := &Email{Host: addr.IP.String(), Port: addr.Port, TLS: TLSNone, To: []string{"desk@example.com"}, Timeout: 200 * time.Millisecond}
+	errs := make(chan error, 1)
+	go func() { errs <- e.Notify(Notification{}) }()
+	select {
+	case err := <-errs:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Errorf("Notify = %v, want a timeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Notify still blocked after the timeout")
+	}
+}
@@ -0,0 +1,169 @@
+// Package alerts notifies the team when the bonds they watch cross a level:
+// rules are evaluated on the quotes of each scrape and the notifications of
+// the rules triggered are delivered to the configured channels.
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/instrument"
+)
+
+// Metric of the quote a rule watches.
+type Metric string
+
+const (
+	// Clean price.
+	Price Metric = "price"
+	// Gross yield to maturity, in percentage.
+	Yield Metric = "yield"
+	// Spread over the fitted curve, in basis points.
+	Spread Metric = "spread"
+)
+
+// Operator comparing the metric to the threshold of a rule.
+type Operator string
+
+const (
+	Above        Operator = ">"
+	AboveOrEqual Operator = ">="
+	Below        Operator = "<"
+	BelowOrEqual Operator = "<="
+)
+
+var (
+	ErrMetric   = fmt.Errorf("metric must be one of %q, %q and %q", Price, Yield, Spread)
+	ErrOperator = fmt.Errorf("operator must be one of %q, %q, %q and %q", Above, AboveOrEqual, Below, BelowOrEqual)
+	ErrCooldown = errors.New("cooldown cannot be negative")
+)
+
+// Rule as stored in the `alert_rules` collection.
+type Rule struct {
+	ID        primitive.ObjectID `json:"ID" bson:"_id,omitempty"`
+	ISIN      string             `json:"ISIN" bson:"ISIN"`
+	Metric    Metric             `json:"Metric" bson:"Metric"`
+	Operator  Operator           `json:"Operator" bson:"Operator"`
+	Threshold float64            `json:"Threshold" bson:"Threshold"`
+	// Minutes to wait after a notification before the rule can trigger again.
+	Cooldown      int        `json:"Cooldown" bson:"Cooldown"`
+	LastTriggered *time.Time `json:"LastTriggered,omitempty" bson:"LastTriggered,omitempty"`
+	CreatedAt     time.Time  `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// Validate checks the fields set by the user.
+func (r *Rule) Validate() error {
+	r.ISIN = strings.ToUpper(strings.TrimSpace(r.ISIN))
+	if err := instrument.ValidateISIN(r.ISIN); err != nil {
+		return err
+	}
+	switch r.Metric {
+	case Price, Yield, Spread:
+	default:
+		return ErrMetric
+	}
+	switch r.Operator {
+	case Above, AboveOrEqual, Below, BelowOrEqual:
+	default:
+		return ErrOperator
+	}
+	if r.Cooldown < 0 {
+		return ErrCooldown
+	}
+	return nil
+}
+
+// Matches reports whether the value satisfies the condition of the rule.
+func (r Rule) Matches(value float64) bool {
+	switch r.Operator {
+	case Above:
+		return value > r.Threshold
+	case AboveOrEqual:
+		return value >= r.Threshold
+	case Below:
+		return value < r.Threshold
+	case BelowOrEqual:
+		return value <= r.Threshold
+	}
+	return false
+}
+
+// Cooling reports whether the rule has triggered less than its cooldown before
+// now.
+func (r Rule) Cooling(now time.Time) bool {
+	if r.LastTriggered == nil {
+		return false
+	}
+	return now.Before(r.LastTriggered.Add(time.Duration(r.Cooldown) * time.Minute))
+}
+
+// Quote of a bond the rules are evaluated on.
+type Quote struct {
+	ISIN        string
+	Description string
+	Price       float64
+	YTM         float64
+	// Nil for the bonds without a spread over the curve.
+	Spread *float64
+}
+
+// Value of the metric of the quote, if the quote has it.
+func (q Quote) Value(m Metric) (float64, bool) {
+	switch m {
+	case Price:
+		return q.Price, q.Price != 0
+	case Yield:
+		return q.YTM, q.YTM != 0
+	case Spread:
+		if q.Spread == nil {
+			return 0, false
+		}
+		return *q.Spread, true
+	}
+	return 0, false
+}
+
+// Notification of a rule triggered by a quote.
+type Notification struct {
+	RuleID      primitive.ObjectID `json:"RuleID" bson:"RuleID"`
+	ISIN        string             `json:"ISIN" bson:"ISIN"`
+	Description string             `json:"Description" bson:"Description"`
+	Metric      Metric             `json:"Metric" bson:"Metric"`
+	Operator    Operator           `json:"Operator" bson:"Operator"`
+	Threshold   float64            `json:"Threshold" bson:"Threshold"`
+	Value       float64            `json:"Value" bson:"Value"`
+	Price       float64            `json:"Price" bson:"Price"`
+	TriggeredAt time.Time          `json:"TriggeredAt" bson:"TriggeredAt"`
+}
+
+// Evaluate returns the notifications of the rules matched by the quotes, by
+// ISIN, skipping the rules still cooling down.
+func Evaluate(rules []Rule, quotes map[string]Quote, now time.Time) []Notification {
+	var notifications []Notification
+	for _, rule := range rules {
+		q, ok := quotes[rule.ISIN]
+		if !ok || rule.Cooling(now) {
+			continue
+		}
+		value, ok := q.Value(rule.Metric)
+		if !ok || !rule.Matches(value) {
+			continue
+		}
+		notifications = append(notifications, Notification{
+			RuleID:      rule.ID,
+			ISIN:        rule.ISIN,
+			Description: q.Description,
+			Metric:      rule.Metric,
+			Operator:    rule.Operator,
+			Threshold:   rule.Threshold,
+			Value:       value,
+			Price:       q.Price,
+			TriggeredAt: now,
+		})
+	}
+	return notifications
+}
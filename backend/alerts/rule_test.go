@@ -0,0 +1,124 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/instrument"
+)
+
+var now = time.Date(2026, time.October, 19, 10, 30, 0, 0, time.UTC)
+
+func spread(bp float64) *float64 {
+	return &bp
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want error
+	}{
+		{"valid", Rule{ISIN: "IT0005240830", Metric: Price, Operator: Below, Cooldown: 60}, nil},
+		{"ISIN is normalized", Rule{ISIN: " it0005240830 ", Metric: Yield, Operator: AboveOrEqual}, nil},
+		{"malformed ISIN", Rule{ISIN: "IT00052408", Metric: Price, Operator: Below}, instrument.ErrISINFormat},
+		{"wrong check digit", Rule{ISIN: "IT0005240831", Metric: Price, Operator: Below}, instrument.ErrISINChecksum},
+		{"unknown metric", Rule{ISIN: "IT0005240830", Metric: "volume", Operator: Below}, ErrMetric},
+		{"unknown operator", Rule{ISIN: "IT0005240830", Metric: Spread, Operator: "=="}, ErrOperator},
+		{"negative cooldown", Rule{ISIN: "IT0005240830", Metric: Price, Operator: Below, Cooldown: -1}, ErrCooldown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			if err := rule.Validate(); !errors.Is(err, tt.want) {
+				t.Fatalf("Validate() = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && rule.ISIN != "IT0005240830" {
+				t.Errorf("ISIN %q, want IT0005240830", rule.ISIN)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		operator Operator
+		value    float64
+		want     bool
+	}{
+		{Above, 101, true},
+		{Above, 100, false},
+		{AboveOrEqual, 100, true},
+		{AboveOrEqual, 99.99, false},
+		{Below, 99, true},
+		{Below, 100, false},
+		{BelowOrEqual, 100, true},
+		{BelowOrEqual, 100.01, false},
+		{"==", 100, false},
+	}
+	for _, tt := range tests {
+		rule := Rule{Operator: tt.operator, Threshold: 100}
+		if got := rule.Matches(tt.value); got != tt.want {
+			t.Errorf("%g %s 100 = %t, want %t", tt.value, tt.operator, got, tt.want)
+		}
+	}
+}
+
+func TestCooling(t *testing.T) {
+	triggered := now.Add(-30 * time.Minute)
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"never triggered", Rule{Cooldown: 60}, false},
+		{"within the cooldown", Rule{Cooldown: 60, LastTriggered: &triggered}, true},
+		{"cooldown over", Rule{Cooldown: 30, LastTriggered: &triggered}, false},
+		{"no cooldown", Rule{LastTriggered: &triggered}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Cooling(now); got != tt.want {
+			t.Errorf("%s: Cooling = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	triggered := now.Add(-10 * time.Minute)
+	rule := func(isin string, metric Metric, operator Operator, threshold float64) Rule {
+		return Rule{ID: primitive.NewObjectID(), ISIN: isin, Metric: metric, Operator: operator, Threshold: threshold}
+	}
+	cooling := rule("IT0005240830", Price, Below, 100)
+	cooling.Cooldown, cooling.LastTriggered = 60, &triggered
+	rules := []Rule{
+		rule("IT0005240830", Price, Below, 100),
+		rule("IT0005240830", Yield, Above, 3),
+		rule("IT0005436693", Spread, AboveOrEqual, 15),
+		// No spread for the BOTs.
+		rule("IT0005603342", Spread, Above, 0),
+		// Not quoted by the scrape.
+		rule("IT0004923998", Price, Below, 200),
+		cooling,
+	}
+	quotes := map[string]Quote{
+		"IT0005240830": {ISIN: "IT0005240830", Description: "Btp-1gn27 2,2%", Price: 98.95, YTM: 2.8},
+		"IT0005436693": {ISIN: "IT0005436693", Price: 86.40, YTM: 3.1, Spread: spread(15)},
+		"IT0005603342": {ISIN: "IT0005603342", Price: 99.78, YTM: 2.1},
+	}
+
+	notifications := Evaluate(rules, quotes, now)
+	if len(notifications) != 2 {
+		t.Fatalf("%d notifications, want 2: %+v", len(notifications), notifications)
+	}
+	price, spreadNotification := notifications[0], notifications[1]
+	if price.RuleID != rules[0].ID || price.Value != 98.95 || price.Description != "Btp-1gn27 2,2%" ||
+		!price.TriggeredAt.Equal(now) {
+		t.Errorf("notification %+v, want the price rule", price)
+	}
+	if spreadNotification.RuleID != rules[2].ID || spreadNotification.Value != 15 || spreadNotification.Price != 86.40 {
+		t.Errorf("notification %+v, want the spread rule", spreadNotification)
+	}
+}
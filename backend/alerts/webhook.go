@@ -0,0 +1,79 @@
+package alerts
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Header of the webhook requests holding the signature of the body.
+const SignatureHeader = "X-BtpTracker-Signature"
+
+// Webhook posts the notifications as JSON to a URL. When Secret is set, the
+// body is signed with HMAC-SHA256 and the signature sent in SignatureHeader as
+// "sha256=<hex digest>", so that the receiver can check where it comes from.
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// WebhookFromEnv reads the webhook from the ALERT_WEBHOOK_URL and
+// ALERT_WEBHOOK_SECRET environment variables. Returns nil if no URL is set.
+func WebhookFromEnv() *Webhook {
+	url := os.Getenv("ALERT_WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+	return &Webhook{
+		URL:    url,
+		Secret: os.Getenv("ALERT_WEBHOOK_SECRET"),
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body with the secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *Webhook) Channel() string {
+	return "webhook"
+}
+
+func (h *Webhook) Notify(n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(h.Secret, body))
+	}
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
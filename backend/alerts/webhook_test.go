@@ -0,0 +1,73 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSignsTheBody(t *testing.T) {
+	const secret = "s3cret"
+	var received Notification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if got, want := r.Header.Get(SignatureHeader), "sha256="+Sign(secret, body); got != want {
+			t.Errorf("signature %q, want %q", got, want)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type %q", ct)
+		}
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n := Notification{ISIN: "IT0005240830", Metric: Price, Operator: Below, Threshold: 100, Value: 98.95}
+	if err := (&Webhook{URL: srv.URL, Secret: secret}).Notify(n); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+	if received.ISIN != n.ISIN || received.Value != n.Value || received.Operator != n.Operator {
+		t.Errorf("received %+v, want %+v", received, n)
+	}
+}
+
+func TestWebhookWithoutSecretIsNotSigned(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sig := r.Header.Get(SignatureHeader); sig != "" {
+			t.Errorf("signature %q without a secret", sig)
+		}
+	}))
+	defer srv.Close()
+	if err := (&Webhook{URL: srv.URL}).Notify(Notification{}); err != nil {
+		t.Fatalf("Notify: %s", err)
+	}
+}
+
+func TestWebhookFailsOnErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	err := (&Webhook{URL: srv.URL}).Notify(Notification{})
+	if err == nil || errors.Unwrap(err) != nil || err.Error() != "webhook responded 503 Service Unavailable" {
+		t.Errorf("Notify = %v, want the status of the response", err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 test case 2 of RFC 4231.
+	got := Sign("Jefe", []byte("what do ya want for nothing?"))
+	if want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"; got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/alerts"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/scraper/replay"
+	"btpTracker/backend/store"
+	"btpTracker/backend/store/memory"
+)
+
+// AlertStore keeping the rules and the deliveries in memory.
+type alertStub struct {
+	mu         sync.Mutex
+	rules      []alerts.Rule
+	deliveries []alerts.Delivery
+}
+
+var _ store.AlertStore = (*alertStub)(nil)
+
+func (a *alertStub) rule(id primitive.ObjectID) (*alerts.Rule, error) {
+	for i := range a.rules {
+		if a.rules[i].ID == id {
+			return &a.rules[i], nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (a *alertStub) CreateAlertRule(r *alerts.Rule) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r.ID = primitive.NewObjectID()
+	a.rules = append(a.rules, *r)
+	return nil
+}
+
+func (a *alertStub) ListAlertRules() ([]alerts.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]alerts.Rule{}, a.rules...), nil
+}
+
+func (a *alertStub) GetAlertRule(id primitive.ObjectID) (*alerts.Rule, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r, err := a.rule(id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *r
+	return &copied, nil
+}
+
+func (a *alertStub) UpdateAlertRule(r alerts.Rule) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stored, err := a.rule(r.ID)
+	if err != nil {
+		return err
+	}
+	r.LastTriggered = stored.LastTriggered
+	*stored = r
+	return nil
+}
+
+func (a *alertStub) DeleteAlertRule(id primitive.ObjectID) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range a.rules {
+		if a.rules[i].ID == id {
+			a.rules = append(a.rules[:i], a.rules[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (a *alertStub) SetAlertTriggered(id primitive.ObjectID, date time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r, err := a.rule(id)
+	if err != nil {
+		return err
+	}
+	r.LastTriggered = &date
+	return nil
+}
+
+func (a *alertStub) InsertAlertDelivery(d *alerts.Delivery) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.deliveries = append(a.deliveries, *d)
+	return nil
+}
+
+func (a *alertStub) ListAlertDeliveries(ruleID primitive.ObjectID) ([]alerts.Delivery, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var deliveries []alerts.Delivery
+	for _, d := range a.deliveries {
+		if d.Notification.RuleID == ruleID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+// Notifier recording the notifications it delivers.
+type recorder struct {
+	mu            sync.Mutex
+	notifications []alerts.Notification
+}
+
+func (r *recorder) Channel() string {
+	return "recorder"
+}
+
+func (r *recorder) Notify(n alerts.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+// ScrapeStore recording the runs it stores.
+type runLog struct {
+	store.ScrapeStore
+	mu   sync.Mutex
+	runs []scraper.Run
+}
+
+func (l *runLog) InsertRun(run scraper.Run) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.runs = append(l.runs, run)
+	return l.ScrapeStore.InsertRun(run)
+}
+
+// Scrape the recorded lists into a memory store, with an alert on the price of
+// a BTP of the lists.
+func TestScrapeAllEvaluatesAlerts(t *testing.T) {
+	srv := replay.NewServer("scraper/testdata")
+	defer srv.Close()
+	replay.Use(srv.URL)
+	t.Cleanup(func() { replay.Use("") })
+
+	rules := &alertStub{}
+	triggered := alerts.Rule{ISIN: "IT0005240830", Metric: alerts.Price, Operator: alerts.Below, Threshold: 100, Cooldown: 60}
+	quiet := alerts.Rule{ISIN: "IT0005240830", Metric: alerts.Price, Operator: alerts.Above, Threshold: 100}
+	for _, r := range []*alerts.Rule{&triggered, &quiet} {
+		if err := rules.CreateAlertRule(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	quotes := memory.New()
+	notifier := &recorder{}
+	runs := &runLog{ScrapeStore: quotes}
+	s := &server{quotes: quotes, scrapes: runs, alerts: rules, notifiers: []alerts.Notifier{notifier}}
+
+	s.scrapeAll()
+	s.deliveries.Wait()
+
+	pages := map[string]int{}
+	for _, run := range runs.runs {
+		pages[run.Source] = run.Pages
+	}
+	if pages["btp"] != 2 || pages["bot"] != 1 {
+		t.Errorf("pages of the runs %v, want 2 for btp and 1 for bot", pages)
+	}
+
+	rows, err := quotes.LatestSnapshot("btp")
+	if err != nil || len(rows) != 6 {
+		t.Fatalf("LatestSnapshot(btp) = %d rows, %v; want the 6 valid rows", len(rows), err)
+	}
+	if _, err := quotes.Instrument("IT0005240830"); err != nil {
+		t.Errorf("Instrument: %s", err)
+	}
+
+	if len(notifier.notifications) != 1 || notifier.notifications[0].RuleID != triggered.ID {
+		t.Fatalf("notifications %+v, want the one of the rule below 100", notifier.notifications)
+	}
+	if n := notifier.notifications[0]; n.Value != 98.95 || n.Description != "Btp-1gn27 2,2%" {
+		t.Errorf("notification %+v, want the price 98.95 of Btp-1gn27 2,2%%", n)
+	}
+	deliveries, _ := rules.ListAlertDeliveries(triggered.ID)
+	if len(deliveries) != 1 || !deliveries[0].Delivered || deliveries[0].Channel != "recorder" {
+		t.Errorf("deliveries %+v, want a delivery logged", deliveries)
+	}
+	if r, _ := rules.GetAlertRule(triggered.ID); r.LastTriggered == nil {
+		t.Errorf("rule %+v not marked as triggered", r)
+	}
+
+	// The rule cools down: the next scrape does not notify it again.
+	s.scrapeAll()
+	s.deliveries.Wait()
+	if len(notifier.notifications) != 1 {
+		t.Errorf("%d notifications after the second scrape, want 1", len(notifier.notifications))
+	}
+}
+
+func TestEvaluateAlertsWithoutStore(t *testing.T) {
+	notifier := &recorder{}
+	s := &server{notifiers: []alerts.Notifier{notifier}}
+	rows := map[string][]store.Row{"btp": {{ISIN: "IT0005240830", Price: 98.95}}}
+	s.evaluateAlerts(rows, nil, time.Now())
+	s.deliveries.Wait()
+	if len(notifier.notifications) != 0 {
+		t.Errorf("notifications %+v without rules", notifier.notifications)
+	}
+}
@@ -0,0 +1,128 @@
+// Package analytics computes yields and risk measures of Italian government
+// bonds from their scraped price, coupon and maturity.
+package analytics
+
+import (
+	"errors"
+	"time"
+
+	"btpTracker/backend/instrument"
+)
+
+// Redemption value of the bonds, per 100 of nominal.
+const Par = 100.0
+
+var (
+	ErrMatured     = errors.New("bond already matured")
+	ErrNoPrice     = errors.New("price must be positive")
+	ErrNoSolution  = errors.New("yield does not converge")
+	ErrNoFrequency = errors.New("coupon frequency must be positive")
+)
+
+// Bond holds the terms of a bond paying a fixed coupon.
+type Bond struct {
+	// Annual coupon rate, in percentage.
+	Coupon float64
+	// Coupons paid per year, zero for zero coupon bonds.
+	Frequency int
+	Maturity  time.Time
+}
+
+// NewBond returns the terms of the instrument.
+func NewBond(inst instrument.Instrument) Bond {
+	return Bond{
+		Coupon:    inst.Coupon,
+		Frequency: inst.CouponFrequency,
+		Maturity:  inst.Maturity,
+	}
+}
+
+// Truncate the time to its date, in UTC.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Days between the two dates, ignoring the time of the day.
+func days(from time.Time, to time.Time) float64 {
+	return day(to).Sub(day(from)).Hours() / 24
+}
+
+// Add months to the date. Days past the end of the resulting month are moved
+// to its last day (31 Aug - 6 months = 28/29 Feb), instead of overflowing to the
+// following month as time.AddDate does.
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(d, last)-1)
+}
+
+// CouponAmount is the coupon paid at each date, per 100 of nominal.
+func (b Bond) CouponAmount() float64 {
+	if b.Frequency == 0 {
+		return 0
+	}
+	return b.Coupon / float64(b.Frequency)
+}
+
+// Period returns the coupon dates surrounding the date: the last coupon paid on
+// or before it and the next one after it. Coupon dates are rolled back from the
+// maturity and are not adjusted for holidays.
+func (b Bond) Period(date time.Time) (prev time.Time, next time.Time, err error) {
+	if b.Frequency <= 0 {
+		return time.Time{}, time.Time{}, ErrNoFrequency
+	}
+	date = day(date)
+	maturity := day(b.Maturity)
+	if !date.Before(maturity) {
+		return time.Time{}, time.Time{}, ErrMatured
+	}
+	step := 12 / b.Frequency
+	next = maturity
+	for n := 1; ; n++ {
+		prev = addMonths(maturity, -step*n)
+		if !prev.After(date) {
+			return prev, next, nil
+		}
+		next = prev
+	}
+}
+
+// CouponDates returns the coupon dates after the date, up to the maturity.
+func (b Bond) CouponDates(date time.Time) ([]time.Time, error) {
+	_, next, err := b.Period(date)
+	if err != nil {
+		return nil, err
+	}
+	// Roll back from the maturity, as rolling forward from the next coupon
+	// would lose the end of month (28 Feb + 6 months = 28 Aug, not 31 Aug).
+	step := 12 / b.Frequency
+	maturity := day(b.Maturity)
+	var dates []time.Time
+	for n := 0; ; n++ {
+		d := addMonths(maturity, -step*n)
+		if d.Before(next) {
+			break
+		}
+		dates = append([]time.Time{d}, dates...)
+	}
+	return dates, nil
+}
+
+// AccruedInterest returns the coupon accrued since the last coupon date, per
+// 100 of nominal, with the ACT/ACT (ICMA) day count: the coupon times the
+// fraction of the current period that has elapsed.
+func (b Bond) AccruedInterest(date time.Time) (float64, error) {
+	if b.Frequency == 0 {
+		if !day(date).Before(day(b.Maturity)) {
+			return 0, ErrMatured
+		}
+		return 0, nil
+	}
+	prev, next, err := b.Period(date)
+	if err != nil {
+		return 0, err
+	}
+	return b.CouponAmount() * days(prev, date) / days(prev, next), nil
+}
@@ -0,0 +1,166 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Compare the floats within the tolerance.
+func near(got float64, want float64, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestAddMonthsKeepsEndOfMonth(t *testing.T) {
+	tests := []struct {
+		from   time.Time
+		months int
+		want   time.Time
+	}{
+		{date(2030, time.August, 31), -6, date(2030, time.February, 28)},
+		{date(2028, time.August, 31), -6, date(2028, time.February, 29)},
+		{date(2030, time.March, 31), -1, date(2030, time.February, 28)},
+		{date(2030, time.June, 1), -6, date(2029, time.December, 1)},
+		{date(2030, time.January, 31), 3, date(2030, time.April, 30)},
+	}
+	for _, tt := range tests {
+		if got := addMonths(tt.from, tt.months); !got.Equal(tt.want) {
+			t.Errorf("addMonths(%s, %d) = %s, want %s", tt.from.Format(time.DateOnly), tt.months,
+				got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	tests := []struct {
+		name       string
+		bond       Bond
+		date       time.Time
+		prev, next time.Time
+	}{
+		{
+			name: "semiannual",
+			bond: Bond{Coupon: 4, Frequency: 2, Maturity: date(2030, time.August, 1)},
+			date: date(2026, time.May, 15),
+			prev: date(2026, time.February, 1),
+			next: date(2026, time.August, 1),
+		},
+		{
+			name: "on a coupon date",
+			bond: Bond{Coupon: 4, Frequency: 2, Maturity: date(2030, time.August, 1)},
+			date: date(2026, time.August, 1),
+			prev: date(2026, time.August, 1),
+			next: date(2027, time.February, 1),
+		},
+		{
+			name: "end of month in a leap year",
+			bond: Bond{Coupon: 3, Frequency: 2, Maturity: date(2030, time.August, 31)},
+			date: date(2028, time.March, 1),
+			prev: date(2028, time.February, 29),
+			next: date(2028, time.August, 31),
+		},
+		{
+			name: "quarterly",
+			bond: Bond{Coupon: 3, Frequency: 4, Maturity: date(2028, time.June, 10)},
+			date: date(2026, time.October, 18),
+			prev: date(2026, time.September, 10),
+			next: date(2026, time.December, 10),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, next, err := tt.bond.Period(tt.date)
+			if err != nil {
+				t.Fatalf("Period: %s", err)
+			}
+			if !prev.Equal(tt.prev) || !next.Equal(tt.next) {
+				t.Errorf("Period = %s, %s; want %s, %s", prev.Format(time.DateOnly), next.Format(time.DateOnly),
+					tt.prev.Format(time.DateOnly), tt.next.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestPeriodErrors(t *testing.T) {
+	matured := Bond{Coupon: 4, Frequency: 2, Maturity: date(2026, time.August, 1)}
+	if _, _, err := matured.Period(date(2026, time.August, 1)); err != ErrMatured {
+		t.Errorf("Period on the maturity: %v, want ErrMatured", err)
+	}
+	zero := Bond{Maturity: date(2027, time.August, 1)}
+	if _, _, err := zero.Period(date(2026, time.August, 1)); err != ErrNoFrequency {
+		t.Errorf("Period of a zero coupon bond: %v, want ErrNoFrequency", err)
+	}
+}
+
+func TestCouponDatesEndOfMonth(t *testing.T) {
+	b := Bond{Coupon: 3, Frequency: 2, Maturity: date(2029, time.August, 31)}
+	got, err := b.CouponDates(date(2027, time.September, 15))
+	if err != nil {
+		t.Fatalf("CouponDates: %s", err)
+	}
+	want := []time.Time{
+		date(2028, time.February, 29),
+		date(2028, time.August, 31),
+		date(2029, time.February, 28),
+		date(2029, time.August, 31),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("CouponDates = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("coupon %d on %s, want %s", i, got[i].Format(time.DateOnly), want[i].Format(time.DateOnly))
+		}
+	}
+}
+
+func TestAccruedInterest(t *testing.T) {
+	tests := []struct {
+		name string
+		bond Bond
+		date time.Time
+		want float64
+	}{
+		{
+			// 90 of the 181 days from 1 Feb to 1 Aug 2026.
+			name: "mid period",
+			bond: Bond{Coupon: 4, Frequency: 2, Maturity: date(2030, time.August, 1)},
+			date: date(2026, time.May, 2),
+			want: 2 * 90.0 / 181,
+		},
+		{
+			name: "on a coupon date",
+			bond: Bond{Coupon: 4, Frequency: 2, Maturity: date(2030, time.August, 1)},
+			date: date(2026, time.August, 1),
+			want: 0,
+		},
+		{
+			// 92 of the 184 days from 28 Feb to 31 Aug 2027.
+			name: "end of month",
+			bond: Bond{Coupon: 5, Frequency: 2, Maturity: date(2030, time.August, 31)},
+			date: date(2027, time.May, 31),
+			want: 2.5 * 92.0 / 184,
+		},
+		{
+			name: "zero coupon",
+			bond: Bond{Maturity: date(2027, time.March, 14)},
+			date: date(2026, time.October, 18),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.bond.AccruedInterest(tt.date)
+			if err != nil {
+				t.Fatalf("AccruedInterest: %s", err)
+			}
+			if !near(got, tt.want, 1e-12) {
+				t.Errorf("AccruedInterest = %.10f, want %.10f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package analytics
+
+import "time"
+
+// DaysToMaturity returns the calendar days from the date to the maturity.
+func (b Bond) DaysToMaturity(date time.Time) int {
+	return int(days(date, b.Maturity))
+}
+
+// SimpleYield returns the annualised simple yield (0.03 is 3%) of a zero
+// coupon bond bought at the price on the date, with the ACT/360 day count used
+// for the BOTs: the discount over the price, scaled to a year of 360 days.
+func (b Bond) SimpleYield(price float64, date time.Time) (float64, error) {
+	if price <= 0 {
+		return 0, ErrNoPrice
+	}
+	left := b.DaysToMaturity(date)
+	if left <= 0 {
+		return 0, ErrMatured
+	}
+	return (Par - price) / price * 360 / float64(left), nil
+}
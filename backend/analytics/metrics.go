@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"time"
+
+	"btpTracker/backend/calendar"
+	"btpTracker/backend/instrument"
+)
+
+// Metrics are the analytics of a quote. Yields are in percentage.
+type Metrics struct {
+	// Gross yield to maturity, compounded annually. For zero coupon bonds this
+	// is the compound yield.
+	YTM float64 `json:"YTM,omitempty" bson:"YTM,omitempty"`
+	// Yield to maturity net of taxes.
+	NetYTM float64 `json:"NetYTM,omitempty" bson:"NetYTM,omitempty"`
+	// Zero coupon bonds only: calendar days left and simple ACT/360 yield.
+	DaysToMaturity int     `json:"DaysToMaturity,omitempty" bson:"DaysToMaturity,omitempty"`
+	SimpleYield    float64 `json:"SimpleYield,omitempty" bson:"SimpleYield,omitempty"`
+	// Risk measures at the gross yield, see Risk.
+	MacaulayDuration float64 `json:"MacaulayDuration,omitempty" bson:"MacaulayDuration,omitempty"`
+	ModifiedDuration float64 `json:"ModifiedDuration,omitempty" bson:"ModifiedDuration,omitempty"`
+	DV01             float64 `json:"DV01,omitempty" bson:"DV01,omitempty"`
+	Convexity        float64 `json:"Convexity,omitempty" bson:"Convexity,omitempty"`
+}
+
+// Compute the metrics of the instrument quoted at the clean price on the trade
+// date. Metrics are computed at the settlement date of the trade.
+func Compute(inst instrument.Instrument, price float64, trade time.Time, tax Taxation) (Metrics, error) {
+	var metrics Metrics
+	bond := NewBond(inst)
+	date := calendar.SettlementDate(trade)
+
+	if bond.Frequency == 0 {
+		simple, err := bond.SimpleYield(price, date)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.DaysToMaturity = bond.DaysToMaturity(date)
+		metrics.SimpleYield = simple * 100
+	}
+
+	ytm, err := bond.YieldToMaturity(price, date)
+	if err != nil {
+		return metrics, err
+	}
+	metrics.YTM = ytm * 100
+
+	risk, err := bond.Risk(price, date)
+	if err != nil {
+		return metrics, err
+	}
+	metrics.MacaulayDuration = risk.Macaulay
+	metrics.ModifiedDuration = risk.Modified
+	metrics.DV01 = risk.DV01
+	metrics.Convexity = risk.Convexity
+
+	net, err := bond.NetYieldToMaturity(price, date, tax)
+	if err != nil {
+		return metrics, err
+	}
+	metrics.NetYTM = net * 100
+	return metrics, nil
+}
@@ -0,0 +1,51 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// Risk measures of a bond, per 100 of nominal.
+type Risk struct {
+	// Average time to the cash flows weighted by their present value, in
+	// years.
+	Macaulay float64
+	// Relative price change for a change of 1 in the yield.
+	Modified float64
+	// Price change for a change of one basis point in the yield.
+	DV01      float64
+	Convexity float64
+}
+
+// Risk returns the risk measures of the bond bought at the clean price on the
+// date, at its gross yield to maturity.
+func (b Bond) Risk(clean float64, date time.Time) (Risk, error) {
+	flows, err := b.CashFlows(date)
+	if err != nil {
+		return Risk{}, err
+	}
+	accrued, err := b.AccruedInterest(date)
+	if err != nil {
+		return Risk{}, err
+	}
+	dirty := clean + accrued
+	y, err := solveYield(flows, dirty)
+	if err != nil {
+		return Risk{}, err
+	}
+
+	var weighted, convexity float64
+	for _, cf := range flows {
+		pv := cf.Amount * math.Pow(1+y, -cf.Time)
+		weighted += cf.Time * pv
+		convexity += cf.Time * (cf.Time + 1) * pv
+	}
+	macaulay := weighted / dirty
+	modified := macaulay / (1 + y)
+	return Risk{
+		Macaulay:  macaulay,
+		Modified:  modified,
+		DV01:      modified * dirty / 10000,
+		Convexity: convexity / (dirty * (1 + y) * (1 + y)),
+	}, nil
+}
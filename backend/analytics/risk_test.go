@@ -0,0 +1,88 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRiskOfZeroCoupon(t *testing.T) {
+	trade := date(2026, time.October, 18)
+	// Two years of 365 days.
+	b := Bond{Maturity: trade.AddDate(0, 0, 730)}
+	price := 94.0
+	risk, err := b.Risk(price, trade)
+	if err != nil {
+		t.Fatalf("Risk: %s", err)
+	}
+	y, err := b.YieldToMaturity(price, trade)
+	if err != nil {
+		t.Fatalf("YieldToMaturity: %s", err)
+	}
+
+	// A single flow: the duration is its time.
+	want := Risk{
+		Macaulay:  2,
+		Modified:  2 / (1 + y),
+		DV01:      2 / (1 + y) * price / 10000,
+		Convexity: 2 * 3 / ((1 + y) * (1 + y)),
+	}
+	if !near(risk.Macaulay, want.Macaulay, 1e-9) || !near(risk.Modified, want.Modified, 1e-9) ||
+		!near(risk.DV01, want.DV01, 1e-12) || !near(risk.Convexity, want.Convexity, 1e-9) {
+		t.Errorf("Risk = %+v, want %+v", risk, want)
+	}
+}
+
+func TestRiskOfParBond(t *testing.T) {
+	// Annual coupons bought at par on a coupon date: the yield is the coupon
+	// and the Macaulay duration has a closed form.
+	b := Bond{Coupon: 5, Frequency: 1, Maturity: date(2031, time.June, 1)}
+	risk, err := b.Risk(Par, date(2026, time.June, 1))
+	if err != nil {
+		t.Fatalf("Risk: %s", err)
+	}
+	y := 0.05
+	n := 5.0
+	macaulay := (1 + y) / y * (1 - 1/math.Pow(1+y, n))
+	if !near(risk.Macaulay, macaulay, 1e-9) {
+		t.Errorf("Macaulay = %.10f, want %.10f", risk.Macaulay, macaulay)
+	}
+	if !near(risk.Modified, macaulay/(1+y), 1e-9) {
+		t.Errorf("Modified = %.10f, want %.10f", risk.Modified, macaulay/(1+y))
+	}
+}
+
+// The DV01 and the convexity match the change of the dirty price for a small
+// change of the yield.
+func TestRiskMatchesPriceChanges(t *testing.T) {
+	b := Bond{Coupon: 3.5, Frequency: 2, Maturity: date(2036, time.March, 1)}
+	trade := date(2026, time.October, 18)
+	clean := 97.3
+	risk, err := b.Risk(clean, trade)
+	if err != nil {
+		t.Fatalf("Risk: %s", err)
+	}
+	flows, err := b.CashFlows(trade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accrued, err := b.AccruedInterest(trade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := solveYield(flows, clean+accrued)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const bp = 0.0001
+	dirty, _ := presentValue(flows, y)
+	up, _ := presentValue(flows, y+bp)
+	down, _ := presentValue(flows, y-bp)
+	if dv01 := (down - up) / 2; !near(risk.DV01, dv01, 1e-6) {
+		t.Errorf("DV01 = %.8f, the price changes by %.8f", risk.DV01, dv01)
+	}
+	if convexity := (up + down - 2*dirty) / (dirty * bp * bp); !near(risk.Convexity, convexity, 1e-2) {
+		t.Errorf("Convexity = %.4f, the price changes give %.4f", risk.Convexity, convexity)
+	}
+}
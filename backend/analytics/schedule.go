@@ -0,0 +1,52 @@
+package analytics
+
+import (
+	"time"
+
+	"btpTracker/backend/calendar"
+)
+
+// Payment of the bond.
+type Payment struct {
+	// Day the payment is made, i.e. the coupon date moved to the following
+	// TARGET2 business day.
+	Date time.Time `json:"Date"`
+	// Coupon date the interest accrues up to, which is not adjusted.
+	CouponDate time.Time `json:"CouponDate"`
+	Coupon     float64   `json:"Coupon"`
+	Redemption float64   `json:"Redemption"`
+	Amount     float64   `json:"Amount"`
+}
+
+// Schedule returns the payments of the bond after the date, for the given
+// nominal (e.g. 1000).
+func (b Bond) Schedule(date time.Time, nominal float64) ([]Payment, error) {
+	var dates []time.Time
+	if b.Frequency == 0 {
+		if !day(date).Before(day(b.Maturity)) {
+			return nil, ErrMatured
+		}
+		dates = []time.Time{day(b.Maturity)}
+	} else {
+		var err error
+		if dates, err = b.CouponDates(date); err != nil {
+			return nil, err
+		}
+	}
+
+	scale := nominal / Par
+	payments := make([]Payment, len(dates))
+	for i, d := range dates {
+		payments[i] = Payment{
+			Date:       calendar.TARGET2.Following(d),
+			CouponDate: d,
+			Coupon:     b.CouponAmount() * scale,
+		}
+	}
+	last := &payments[len(payments)-1]
+	last.Redemption = nominal
+	for i := range payments {
+		payments[i].Amount = payments[i].Coupon + payments[i].Redemption
+	}
+	return payments, nil
+}
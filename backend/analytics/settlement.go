@@ -0,0 +1,34 @@
+package analytics
+
+import (
+	"time"
+
+	"btpTracker/backend/calendar"
+)
+
+// Settlement of a purchase of the bond, per 100 of nominal.
+type Settlement struct {
+	TradeDate       time.Time `json:"TradeDate"`
+	SettlementDate  time.Time `json:"SettlementDate"`
+	CleanPrice      float64   `json:"CleanPrice"`
+	AccruedInterest float64   `json:"AccruedInterest"`
+	DirtyPrice      float64   `json:"DirtyPrice"`
+}
+
+// Settle returns what is paid for the bond bought at the clean price on the
+// trade date: the clean price plus the interest accrued up to the settlement
+// date, T+2 on the Italian calendar.
+func (b Bond) Settle(clean float64, trade time.Time) (Settlement, error) {
+	settlement := calendar.SettlementDate(trade)
+	accrued, err := b.AccruedInterest(settlement)
+	if err != nil {
+		return Settlement{}, err
+	}
+	return Settlement{
+		TradeDate:       day(trade),
+		SettlementDate:  settlement,
+		CleanPrice:      clean,
+		AccruedInterest: accrued,
+		DirtyPrice:      clean + accrued,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Taxation of the bond held by an Italian retail investor.
+type Taxation struct {
+	// Withholding tax on coupons and on the gain at redemption (0.125 is
+	// 12.5%).
+	Rate float64
+	// Annual stamp duty on the market value (0.002 is 0.2%). Zero when it does
+	// not apply.
+	StampDuty float64
+}
+
+// Government bonds are taxed at 12.5%, half of the rate of the other financial
+// income. Stamp duty is only due on securities deposited with an Italian
+// intermediary, so it is off by default.
+var DefaultTaxation = Taxation{Rate: 0.125}
+
+// Read a rate in percentage from the environment variable `name`.
+func percentFromEnv(name string, fallback float64) (float64, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback, fmt.Errorf("%s: %w", name, err)
+	}
+	return parsed / 100, nil
+}
+
+// TaxationFromEnv reads the taxation from the TAX_RATE and STAMP_DUTY_RATE
+// environment variables, both in percentage (e.g. TAX_RATE=12.5 and
+// STAMP_DUTY_RATE=0.2). Missing variables keep the value of DefaultTaxation.
+func TaxationFromEnv() (Taxation, error) {
+	rate, err := percentFromEnv("TAX_RATE", DefaultTaxation.Rate)
+	if err != nil {
+		return DefaultTaxation, err
+	}
+	stampDuty, err := percentFromEnv("STAMP_DUTY_RATE", DefaultTaxation.StampDuty)
+	if err != nil {
+		return DefaultTaxation, err
+	}
+	return Taxation{Rate: rate, StampDuty: stampDuty}, nil
+}
+
+// NetCashFlows returns the cash flows of the bond bought at the clean price on
+// the date, net of taxes:
+//   - coupons are paid net of the withholding tax;
+//   - the redemption is reduced by the tax on the gain over the clean price,
+//     which covers the issue discount (losses are not refunded);
+//   - the stamp duty, computed on the clean price, is charged pro rata on every
+//     payment for the time elapsed since the previous one.
+func (b Bond) NetCashFlows(clean float64, date time.Time, tax Taxation) ([]CashFlow, error) {
+	flows, err := b.CashFlows(date)
+	if err != nil {
+		return nil, err
+	}
+	previous := 0.0
+	for i := range flows {
+		coupon := b.CouponAmount()
+		flows[i].Amount -= coupon * tax.Rate
+		flows[i].Amount -= clean * tax.StampDuty * (flows[i].Time - previous)
+		previous = flows[i].Time
+	}
+	last := len(flows) - 1
+	flows[last].Amount -= max(Par-clean, 0) * tax.Rate
+	return flows, nil
+}
+
+// NetYieldToMaturity returns the annual yield to maturity (0.03 is 3%) of the
+// net cash flows of the bond bought at the clean price on the date.
+func (b Bond) NetYieldToMaturity(clean float64, date time.Time, tax Taxation) (float64, error) {
+	flows, err := b.NetCashFlows(clean, date, tax)
+	if err != nil {
+		return 0, err
+	}
+	accrued, err := b.AccruedInterest(date)
+	if err != nil {
+		return 0, err
+	}
+	return solveYield(flows, clean+accrued)
+}
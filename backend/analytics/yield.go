@@ -0,0 +1,120 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// CashFlow is a payment of the bond, per 100 of nominal, due in Time years.
+type CashFlow struct {
+	Date   time.Time
+	Time   float64
+	Amount float64
+}
+
+// CashFlows returns the coupons and the redemption paid after the date. Times
+// follow the ICMA convention: the current period counts for the fraction left
+// of it, every following one for 1/frequency of year. Zero coupon bonds only
+// pay the redemption, at ACT/365.
+func (b Bond) CashFlows(date time.Time) ([]CashFlow, error) {
+	if b.Frequency == 0 {
+		left := b.DaysToMaturity(date)
+		if left <= 0 {
+			return nil, ErrMatured
+		}
+		return []CashFlow{{Date: day(b.Maturity), Time: float64(left) / 365, Amount: Par}}, nil
+	}
+	prev, next, err := b.Period(date)
+	if err != nil {
+		return nil, err
+	}
+	dates, err := b.CouponDates(date)
+	if err != nil {
+		return nil, err
+	}
+
+	f := float64(b.Frequency)
+	left := days(date, next) / days(prev, next)
+	flows := make([]CashFlow, len(dates))
+	for i, d := range dates {
+		flows[i] = CashFlow{Date: d, Time: (left + float64(i)) / f, Amount: b.CouponAmount()}
+	}
+	flows[len(flows)-1].Amount += Par
+	return flows, nil
+}
+
+// Present value of the cash flows at the annual yield y (0.03 is 3%), and its
+// derivative with respect to y.
+func presentValue(flows []CashFlow, y float64) (pv float64, dpv float64) {
+	for _, cf := range flows {
+		discount := math.Pow(1+y, -cf.Time)
+		pv += cf.Amount * discount
+		dpv -= cf.Time * cf.Amount * discount / (1 + y)
+	}
+	return pv, dpv
+}
+
+// Yield solves presentValue(flows, y) = price, with Newton's method falling
+// back to bisection when it leaves the range of sensible yields.
+func solveYield(flows []CashFlow, price float64) (float64, error) {
+	if price <= 0 {
+		return 0, ErrNoPrice
+	}
+	const (
+		tolerance = 1e-10
+		low       = -0.5
+		high      = 2.0
+	)
+
+	y := 0.03
+	for i := 0; i < 50; i++ {
+		pv, dpv := presentValue(flows, y)
+		if math.Abs(pv-price) < tolerance {
+			return y, nil
+		}
+		if dpv == 0 {
+			break
+		}
+		y -= (pv - price) / dpv
+		if y <= low || y >= high || math.IsNaN(y) {
+			break
+		}
+	}
+
+	// The present value decreases with the yield.
+	lo, hi := low, high
+	if pv, _ := presentValue(flows, lo); pv < price {
+		return 0, ErrNoSolution
+	}
+	if pv, _ := presentValue(flows, hi); pv > price {
+		return 0, ErrNoSolution
+	}
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		pv, _ := presentValue(flows, mid)
+		if math.Abs(pv-price) < tolerance {
+			return mid, nil
+		}
+		if pv > price {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
+
+// YieldToMaturity returns the gross annual yield to maturity (0.03 is 3%) of
+// the bond bought at the clean price on the date, compounding annually as the
+// Italian Treasury does for its "rendimento effettivo lordo".
+func (b Bond) YieldToMaturity(clean float64, date time.Time) (float64, error) {
+	flows, err := b.CashFlows(date)
+	if err != nil {
+		return 0, err
+	}
+	accrued, err := b.AccruedInterest(date)
+	if err != nil {
+		return 0, err
+	}
+	return solveYield(flows, clean+accrued)
+}
@@ -0,0 +1,198 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSolveYield(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []CashFlow
+		price float64
+		want  float64
+	}{
+		{"one year", []CashFlow{{Time: 1, Amount: 105}}, 100, 0.05},
+		{"two years", []CashFlow{{Time: 2, Amount: 100}}, 100 / 1.03 / 1.03, 0.03},
+		{"negative yield", []CashFlow{{Time: 1, Amount: 100}}, 101, 100.0/101 - 1},
+		{"half year", []CashFlow{{Time: 0.5, Amount: 100}}, 100 / math.Sqrt(1.04), 0.04},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := solveYield(tt.flows, tt.price)
+			if err != nil {
+				t.Fatalf("solveYield: %s", err)
+			}
+			if !near(got, tt.want, 1e-9) {
+				t.Errorf("solveYield = %.12f, want %.12f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolveYieldErrors(t *testing.T) {
+	flows := []CashFlow{{Time: 1, Amount: 105}}
+	if _, err := solveYield(flows, 0); err != ErrNoPrice {
+		t.Errorf("price 0: %v, want ErrNoPrice", err)
+	}
+	// Even a yield of -50% does not get the value of the flows to 1000.
+	if _, err := solveYield(flows, 1000); err != ErrNoSolution {
+		t.Errorf("price 1000: %v, want ErrNoSolution", err)
+	}
+}
+
+func TestYieldToMaturityOfParBond(t *testing.T) {
+	tests := []struct {
+		name string
+		bond Bond
+		want float64
+	}{
+		// Semiannual coupons of 2% compound to 4.04% a year.
+		{"semiannual", Bond{Coupon: 4, Frequency: 2, Maturity: date(2031, time.June, 1)}, 1.02*1.02 - 1},
+		{"quarterly", Bond{Coupon: 3, Frequency: 4, Maturity: date(2031, time.June, 1)}, math.Pow(1.0075, 4) - 1},
+		{"annual", Bond{Coupon: 2.5, Frequency: 1, Maturity: date(2031, time.June, 1)}, 0.025},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// On a coupon date there is no accrued interest: the bond is
+			// bought at par.
+			got, err := tt.bond.YieldToMaturity(Par, date(2026, time.June, 1))
+			if err != nil {
+				t.Fatalf("YieldToMaturity: %s", err)
+			}
+			if !near(got, tt.want, 1e-9) {
+				t.Errorf("YieldToMaturity = %.10f, want %.10f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYieldToMaturityFallsWithPrice(t *testing.T) {
+	b := Bond{Coupon: 3, Frequency: 2, Maturity: date(2034, time.September, 1)}
+	trade := date(2026, time.October, 18)
+	previous := math.Inf(1)
+	for _, price := range []float64{90, 95, 100, 105, 110} {
+		y, err := b.YieldToMaturity(price, trade)
+		if err != nil {
+			t.Fatalf("YieldToMaturity(%g): %s", price, err)
+		}
+		if y >= previous {
+			t.Errorf("YieldToMaturity(%g) = %g, not below the yield at a lower price %g", price, y, previous)
+		}
+		previous = y
+	}
+}
+
+func TestNetYieldToMaturity(t *testing.T) {
+	trade := date(2026, time.June, 1)
+	tests := []struct {
+		name  string
+		bond  Bond
+		clean float64
+		tax   Taxation
+		want  float64
+	}{
+		{
+			name:  "no tax",
+			bond:  Bond{Coupon: 4, Frequency: 2, Maturity: date(2031, time.June, 1)},
+			clean: Par,
+			want:  1.02*1.02 - 1,
+		},
+		{
+			// Coupons of 2% taxed at 12.5% compound to 3.535%.
+			name:  "par bond",
+			bond:  Bond{Coupon: 4, Frequency: 2, Maturity: date(2031, time.June, 1)},
+			clean: Par,
+			tax:   Taxation{Rate: 0.125},
+			want:  1.0175*1.0175 - 1,
+		},
+		{
+			// The gain of 5 over the price is taxed at maturity.
+			name:  "zero coupon",
+			bond:  Bond{Maturity: trade.AddDate(0, 0, 365)},
+			clean: 95,
+			tax:   Taxation{Rate: 0.125},
+			want:  (Par-5*0.125)/95 - 1,
+		},
+		{
+			// The stamp duty of 0.2% a year of the price is paid with the
+			// redemption.
+			name:  "stamp duty",
+			bond:  Bond{Maturity: trade.AddDate(0, 0, 365)},
+			clean: 95,
+			tax:   Taxation{StampDuty: 0.002},
+			want:  (Par-95*0.002)/95 - 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.bond.NetYieldToMaturity(tt.clean, trade, tt.tax)
+			if err != nil {
+				t.Fatalf("NetYieldToMaturity: %s", err)
+			}
+			if !near(got, tt.want, 1e-9) {
+				t.Errorf("NetYieldToMaturity = %.10f, want %.10f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimpleYield(t *testing.T) {
+	trade := date(2026, time.October, 18)
+	b := Bond{Maturity: trade.AddDate(0, 0, 180)}
+	got, err := b.SimpleYield(99, trade)
+	if err != nil {
+		t.Fatalf("SimpleYield: %s", err)
+	}
+	// The discount of 1 over 99, for half of a year of 360 days.
+	if want := 1.0 / 99 * 2; !near(got, want, 1e-12) {
+		t.Errorf("SimpleYield = %.10f, want %.10f", got, want)
+	}
+
+	if _, err := b.SimpleYield(0, trade); err != ErrNoPrice {
+		t.Errorf("price 0: %v, want ErrNoPrice", err)
+	}
+	if _, err := b.SimpleYield(99, b.Maturity); err != ErrMatured {
+		t.Errorf("on the maturity: %v, want ErrMatured", err)
+	}
+}
+
+func TestCashFlowTimes(t *testing.T) {
+	b := Bond{Coupon: 4, Frequency: 2, Maturity: date(2027, time.August, 1)}
+	// 90 of the 181 days of the period have elapsed.
+	flows, err := b.CashFlows(date(2026, time.May, 2))
+	if err != nil {
+		t.Fatalf("CashFlows: %s", err)
+	}
+	left := 91.0 / 181
+	want := []CashFlow{
+		{Date: date(2026, time.August, 1), Time: left / 2, Amount: 2},
+		{Date: date(2027, time.February, 1), Time: (left + 1) / 2, Amount: 2},
+		{Date: date(2027, time.August, 1), Time: (left + 2) / 2, Amount: 102},
+	}
+	if len(flows) != len(want) {
+		t.Fatalf("CashFlows = %+v, want %+v", flows, want)
+	}
+	for i, cf := range flows {
+		if !cf.Date.Equal(want[i].Date) || !near(cf.Time, want[i].Time, 1e-12) || cf.Amount != want[i].Amount {
+			t.Errorf("flow %d = %+v, want %+v", i, cf, want[i])
+		}
+	}
+}
+
+// The yields are quoted for any time of the day of the trade.
+func TestYieldIgnoresTimeOfDay(t *testing.T) {
+	b := Bond{Coupon: 3, Frequency: 2, Maturity: date(2034, time.September, 1)}
+	midnight, err := b.YieldToMaturity(98, date(2026, time.October, 18))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evening, err := b.YieldToMaturity(98, date(2026, time.October, 18).Add(20*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if midnight != evening {
+		t.Errorf("YieldToMaturity at midnight %g, in the evening %g", midnight, evening)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"btpTracker/backend/analytics"
+	"btpTracker/backend/ical"
+	"btpTracker/backend/instrument"
+	"btpTracker/backend/store"
+)
+
+const bondsRoute = "/api/v1/bonds/"
+
+// Write the value as a JSON response.
+func writeJSON(w http.ResponseWriter, value any) {
+	responseJSON, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, "Error encoding JSON", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(responseJSON)
+}
+
+// Look up the instrument of the ISIN, writing the error if it cannot be found.
+func (s *server) findInstrument(w http.ResponseWriter, isin string) (*instrument.Instrument, bool) {
+	inst, err := s.quotes.Instrument(isin)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, fmt.Sprintf("Unknown ISIN %s", isin), http.StatusNotFound)
+		return nil, false
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Error while retrieving %s: %s", isin, err), http.StatusInternalServerError)
+		return nil, false
+	}
+	return inst, true
+}
+
+// Routes /api/v1/bonds/{isin}/{resource} to the handler of the resource.
+func (s *server) bondsHandler(w http.ResponseWriter, r *http.Request) {
+	// Enable CORS.
+	(w).Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, bondsRoute), "/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	isin, resource := parts[0], parts[1]
+
+	switch resource {
+	case "risk":
+		s.getBondRisk(w, r, isin)
+	case "settlement":
+		s.getBondSettlement(w, r, isin)
+	case "cashflows":
+		s.getBondCashFlows(w, r, isin)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// RiskPoint is a row of the time series of the risk measures of a bond.
+type RiskPoint struct {
+	Name             time.Time `json:"name"`
+	MacaulayDuration float64   `json:"macaulayDuration,omitempty"`
+	ModifiedDuration float64   `json:"modifiedDuration,omitempty"`
+	DV01             float64   `json:"dv01,omitempty"`
+	Convexity        float64   `json:"convexity,omitempty"`
+}
+
+// Time series of the duration, DV01 and convexity of the bond.
+func (s *server) getBondRisk(w http.ResponseWriter, r *http.Request, isin string) {
+	inst, ok := s.findInstrument(w, isin)
+	if !ok {
+		return
+	}
+	rows, err := s.quotes.History(inst.Source, isin, time.Time{}, time.Time{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error while retrieving the risk of %s: %s", isin, err), http.StatusInternalServerError)
+		return
+	}
+	points := make([]RiskPoint, len(rows))
+	for i, row := range rows {
+		points[i] = RiskPoint{
+			Name:             row.InsertionDate,
+			MacaulayDuration: row.MacaulayDuration,
+			ModifiedDuration: row.ModifiedDuration,
+			DV01:             row.DV01,
+			Convexity:        row.Convexity,
+		}
+	}
+	writeJSON(w, points)
+}
+
+// Accrued interest and dirty price of the bond bought at its last price of the
+// trade date.
+//
+// This route accepts the following query parameters:
+//   - 'date': the trade date, as YYYY-MM-DD. Defaults to today. The settlement
+//     date is two business days later.
+func (s *server) getBondSettlement(w http.ResponseWriter, r *http.Request, isin string) {
+	trade := time.Now()
+	before := trade
+	date, err := dateParam(r, "date")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !date.IsZero() {
+		// The last price stored on or before the trade date.
+		trade = date
+		before = date.AddDate(0, 0, 1)
+	}
+
+	inst, ok := s.findInstrument(w, isin)
+	if !ok {
+		return
+	}
+	latest, err := s.quotes.Latest(inst.Source, isin, before)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, fmt.Sprintf("No price of %s on or before %s", isin, trade.Format(time.DateOnly)), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Error while retrieving the price of %s: %s", isin, err), http.StatusInternalServerError)
+		return
+	}
+
+	settlement, err := analytics.NewBond(*inst).Settle(latest.Price, trade)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot settle %s: %s", isin, err), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, struct {
+		ISIN string `json:"ISIN"`
+		analytics.Settlement
+	}{isin, settlement})
+}
+
+// Nominal the cash flows are computed for, if not given.
+const defaultNominal = 1000.0
+
+// Coupons and redemption the bond still has to pay.
+//
+// This route accepts the following query parameters:
+//   - 'nominal': the nominal the amounts refer to. Defaults to 1000.
+//   - 'format': "json" (the default) or "ics" for an iCalendar file.
+func (s *server) getBondCashFlows(w http.ResponseWriter, r *http.Request, isin string) {
+	queryValues := r.URL.Query()
+	nominal := defaultNominal
+	if n := queryValues.Get("nominal"); n != "" {
+		parsed, err := strconv.ParseFloat(n, 64)
+		if err != nil || parsed <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid nominal %s", n), http.StatusBadRequest)
+			return
+		}
+		nominal = parsed
+	}
+	format := queryValues.Get("format")
+	if format != "" && format != "json" && format != "ics" {
+		http.Error(w, fmt.Sprintf("Invalid format %s", format), http.StatusBadRequest)
+		return
+	}
+
+	inst, ok := s.findInstrument(w, isin)
+	if !ok {
+		return
+	}
+	payments, err := analytics.NewBond(*inst).Schedule(time.Now(), nominal)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot compute the cash flows of %s: %s", isin, err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if format != "ics" {
+		writeJSON(w, payments)
+		return
+	}
+	events := make([]ical.Event, len(payments))
+	for i, p := range payments {
+		summary := fmt.Sprintf("%s coupon %.2f", inst.Description, p.Coupon)
+		if p.Redemption > 0 {
+			summary = fmt.Sprintf("%s redemption %.2f", inst.Description, p.Amount)
+		}
+		events[i] = ical.Event{
+			UID:         fmt.Sprintf("%s-%s@btptracker", isin, p.CouponDate.Format("20060102")),
+			Date:        p.Date,
+			Summary:     summary,
+			Description: fmt.Sprintf("%s: coupon %.2f, redemption %.2f per %.0f nominal", isin, p.Coupon, p.Redemption, nominal),
+		}
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.ics", isin))
+	if err := ical.Write(w, inst.Description, events); err != nil {
+		log.Printf("Error while writing the calendar of %s: %s\n", isin, err)
+	}
+}
@@ -0,0 +1,118 @@
+// Package calendar tells business days from holidays on the calendars used to
+// settle and pay Italian government bonds.
+package calendar
+
+import "time"
+
+// Calendar of the business days of a market.
+type Calendar struct {
+	Name string
+	// Holidays of the year, besides the weekends.
+	holidays func(year int) []time.Time
+}
+
+// Truncate the time to its date, in UTC.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func date(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Easter returns the Easter Sunday of the year, with the anonymous Gregorian
+// algorithm.
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	d0 := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), d0)
+}
+
+// TARGET2 is the calendar of the euro payment system, used to pay coupons and
+// redemptions: New Year's Day, Good Friday, Easter Monday, Labour Day,
+// Christmas and Boxing Day.
+var TARGET2 = Calendar{
+	Name: "TARGET2",
+	holidays: func(year int) []time.Time {
+		easter := Easter(year)
+		return []time.Time{
+			date(year, time.January, 1),
+			easter.AddDate(0, 0, -2),
+			easter.AddDate(0, 0, 1),
+			date(year, time.May, 1),
+			date(year, time.December, 25),
+			date(year, time.December, 26),
+		}
+	},
+}
+
+// Italy is the calendar of Borsa Italiana, where the MOT trades and settles:
+// the TARGET2 holidays plus Ferragosto, Christmas Eve and New Year's Eve.
+var Italy = Calendar{
+	Name: "Italy",
+	holidays: func(year int) []time.Time {
+		return append(TARGET2.holidays(year),
+			date(year, time.August, 15),
+			date(year, time.December, 24),
+			date(year, time.December, 31),
+		)
+	},
+}
+
+// IsBusinessDay reports whether the date is neither a weekend nor a holiday.
+func (c Calendar) IsBusinessDay(t time.Time) bool {
+	t = day(t)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	for _, holiday := range c.holidays(t.Year()) {
+		if t.Equal(holiday) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddBusinessDays moves the date forward by n business days.
+func (c Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	t = day(t)
+	for n > 0 {
+		t = t.AddDate(0, 0, 1)
+		if c.IsBusinessDay(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// Following returns the date if it is a business day, otherwise the first
+// business day after it.
+func (c Calendar) Following(t time.Time) time.Time {
+	t = day(t)
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// Days between the trade and the settlement of bonds on the MOT.
+const SettlementDays = 2
+
+// SettlementDate returns the date a trade made on the date settles: two
+// business days later on the Italian calendar (T+2).
+func SettlementDate(trade time.Time) time.Time {
+	return Italy.AddBusinessDays(trade, SettlementDays)
+}
@@ -0,0 +1,98 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	tests := []struct {
+		year int
+		want time.Time
+	}{
+		{2008, date(2008, time.March, 23)},
+		{2024, date(2024, time.March, 31)},
+		{2025, date(2025, time.April, 20)},
+		{2026, date(2026, time.April, 5)},
+		{2027, date(2027, time.March, 28)},
+		// The latest possible Easter.
+		{2038, date(2038, time.April, 25)},
+	}
+	for _, tt := range tests {
+		if got := Easter(tt.year); !got.Equal(tt.want) {
+			t.Errorf("Easter(%d) = %s, want %s", tt.year, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestIsBusinessDay(t *testing.T) {
+	rome := time.FixedZone("CET", 3600)
+	tests := []struct {
+		name     string
+		calendar Calendar
+		day      time.Time
+		want     bool
+	}{
+		{"weekday", Italy, date(2026, time.October, 19), true},
+		{"saturday", Italy, date(2026, time.October, 17), false},
+		{"good friday", TARGET2, date(2026, time.April, 3), false},
+		{"easter monday", Italy, date(2026, time.April, 6), false},
+		{"ferragosto", Italy, date(2025, time.August, 15), false},
+		{"ferragosto is paid", TARGET2, date(2025, time.August, 15), true},
+		{"christmas eve", Italy, date(2026, time.December, 24), false},
+		{"christmas eve is paid", TARGET2, date(2026, time.December, 24), true},
+		{"boxing day", TARGET2, date(2025, time.December, 26), false},
+		// The day is the one of the time zone of the time, not of UTC.
+		{"late on christmas eve", Italy, time.Date(2026, time.December, 24, 0, 30, 0, 0, rome), false},
+	}
+	for _, tt := range tests {
+		if got := tt.calendar.IsBusinessDay(tt.day); got != tt.want {
+			t.Errorf("%s: IsBusinessDay(%s) on %s = %t, want %t", tt.name, tt.day, tt.calendar.Name, got, tt.want)
+		}
+	}
+}
+
+func TestSettlementDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		trade time.Time
+		want  time.Time
+	}{
+		{"monday", date(2026, time.October, 19), date(2026, time.October, 21)},
+		{"friday", date(2026, time.October, 16), date(2026, time.October, 20)},
+		{"saturday", date(2026, time.October, 17), date(2026, time.October, 20)},
+		{"before christmas", date(2026, time.December, 23), date(2026, time.December, 29)},
+		{"before new year", date(2026, time.December, 30), date(2027, time.January, 5)},
+		{"before easter", date(2026, time.April, 2), date(2026, time.April, 8)},
+		{"before ferragosto", date(2025, time.August, 14), date(2025, time.August, 19)},
+		{"time of the day", date(2026, time.October, 19).Add(17 * time.Hour), date(2026, time.October, 21)},
+	}
+	for _, tt := range tests {
+		if got := SettlementDate(tt.trade); !got.Equal(tt.want) {
+			t.Errorf("%s: SettlementDate(%s) = %s, want %s", tt.name, tt.trade.Format(time.DateOnly),
+				got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestFollowing(t *testing.T) {
+	tests := []struct {
+		calendar Calendar
+		day      time.Time
+		want     time.Time
+	}{
+		{Italy, date(2026, time.October, 19), date(2026, time.October, 19)},
+		{Italy, date(2026, time.October, 17), date(2026, time.October, 19)},
+		{TARGET2, date(2026, time.December, 24), date(2026, time.December, 24)},
+		{Italy, date(2026, time.December, 24), date(2026, time.December, 28)},
+		{TARGET2, date(2026, time.December, 25), date(2026, time.December, 28)},
+		{TARGET2, date(2027, time.January, 1), date(2027, time.January, 4)},
+		{TARGET2, date(2026, time.April, 3), date(2026, time.April, 7)},
+	}
+	for _, tt := range tests {
+		if got := tt.calendar.Following(tt.day); !got.Equal(tt.want) {
+			t.Errorf("Following(%s) on %s = %s, want %s", tt.day.Format(time.DateOnly), tt.calendar.Name,
+				got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package curve fits the Italian government yield curve to the yields of the
+// BTPs quoted on the MOT.
+package curve
+
+import (
+	"math"
+	"time"
+
+	"btpTracker/backend/instrument"
+)
+
+// Observation is a bond of the snapshot the curve is fitted to.
+type Observation struct {
+	ISIN      string
+	IssueType instrument.IssueType
+	Maturity  time.Time
+	// Gross yield to maturity, in percentage.
+	YTM float64
+}
+
+// Point of the curve at a tenor, in years. Yields are in percentage.
+type Point struct {
+	Tenor float64 `json:"Tenor" bson:"Tenor"`
+	// Yield of the fitted curve, i.e. of a bond maturing at the tenor.
+	Yield float64 `json:"Yield" bson:"Yield"`
+	// Zero coupon rate bootstrapped from the fitted curve, at the whole years
+	// only: the bootstrap has annual steps, so the shorter tenors have none.
+	// Where the curve is too steep to be bootstrapped, it is the yield.
+	Zero *float64 `json:"Zero,omitempty" bson:"Zero,omitempty"`
+}
+
+// Curve fitted to the snapshot taken at Date, as stored in the `curves`
+// collection.
+type Curve struct {
+	Date  time.Time `json:"Date" bson:"Date"`
+	Model NSS       `json:"Model" bson:"Model"`
+	// Bonds the curve was fitted to and the root mean squared error of the fit.
+	Bonds  int     `json:"Bonds" bson:"Bonds"`
+	RMSE   float64 `json:"RMSE" bson:"RMSE"`
+	Points []Point `json:"Points" bson:"Points"`
+}
+
+// Tenors of the points of the curve, up to the longest bond of the snapshot.
+var Tenors = []float64{0.25, 0.5, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 15, 20, 25, 30, 40, 50}
+
+// Bonds closer to maturity than this, in years, trade on technicalities rather
+// than on rates and are left out of the fit.
+const minTenor = 0.5
+
+// Years from the date to the maturity.
+func YearsTo(date time.Time, maturity time.Time) float64 {
+	return maturity.Sub(date).Hours() / 24 / 365.25
+}
+
+// Eligible reports whether the bond is used to fit the curve: only nominal
+// fixed coupon BTPs with a yield enter it, as inflation linked, retail and
+// floating bonds price different risks.
+//
+// Liquidity is not filtered: the MOT lists carry no volumes, so a bond traded
+// once in the day weighs as much as the benchmarks. The yield of a bond that
+// did not trade at all is never computed, since its row is rejected.
+func Eligible(obs Observation, date time.Time) bool {
+	return obs.IssueType == instrument.BTP && obs.YTM != 0 && YearsTo(date, obs.Maturity) >= minTenor
+}
+
+// Build fits the curve to the eligible bonds of the snapshot taken at the date.
+func Build(date time.Time, snapshot []Observation) (*Curve, error) {
+	var tenors, yields []float64
+	longest := 0.0
+	for _, obs := range snapshot {
+		if !Eligible(obs, date) {
+			continue
+		}
+		t := YearsTo(date, obs.Maturity)
+		tenors = append(tenors, t)
+		yields = append(yields, obs.YTM)
+		longest = math.Max(longest, t)
+	}
+
+	model, rmse, err := FitNSS(tenors, yields)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := &Curve{Date: date, Model: model, Bonds: len(tenors), RMSE: rmse}
+	zeros := bootstrap(model, int(math.Ceil(longest)))
+	for _, t := range Tenors {
+		if t > math.Ceil(longest) {
+			break
+		}
+		point := Point{Tenor: t, Yield: model.Yield(t)}
+		if t >= 1 && t == math.Trunc(t) {
+			point.Zero = &zeros[int(t)-1]
+		}
+		curve.Points = append(curve.Points, point)
+	}
+	return curve, nil
+}
+
+// Bootstrap the annual zero coupon rates from 1 to `years`, reading the fitted
+// curve as the yields of bonds paying annual coupons equal to their yield
+// (i.e. priced at par).
+func bootstrap(model NSS, years int) []float64 {
+	zeros := make([]float64, years)
+	discounts := 0.0
+	for n := 1; n <= years; n++ {
+		c := model.Yield(float64(n)) / 100
+		df := (1 - c*discounts) / (1 + c)
+		if df <= 0 {
+			// The fitted curve is too steep to be priced at par: keep the
+			// yields of the curve from here on.
+			for ; n <= years; n++ {
+				zeros[n-1] = model.Yield(float64(n))
+			}
+			break
+		}
+		discounts += df
+		zeros[n-1] = (math.Pow(df, -1/float64(n)) - 1) * 100
+	}
+	return zeros
+}
@@ -0,0 +1,71 @@
+package curve
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"btpTracker/backend/instrument"
+)
+
+func TestBootstrapFlatCurve(t *testing.T) {
+	for i, zero := range bootstrap(NSS{Beta0: 3, Tau1: 1, Tau2: 2}, 30) {
+		if math.Abs(zero-3) > 1e-9 {
+			t.Errorf("zero rate at %d years = %g, want 3", i+1, zero)
+		}
+	}
+}
+
+// A bond paying annual coupons equal to the fitted yield is priced at par by
+// the bootstrapped zero rates.
+func TestBootstrapPricesParBonds(t *testing.T) {
+	model := NSS{Beta0: 4.2, Beta1: -2.1, Beta2: 1.5, Beta3: -1.2, Tau1: 2, Tau2: 10}
+	zeros := bootstrap(model, 30)
+	for n := 1; n <= len(zeros); n++ {
+		coupon := model.Yield(float64(n)) / 100
+		price := 0.0
+		for k := 1; k <= n; k++ {
+			price += coupon * math.Pow(1+zeros[k-1]/100, -float64(k))
+		}
+		price += math.Pow(1+zeros[n-1]/100, -float64(n))
+		if math.Abs(price-1) > 1e-9 {
+			t.Errorf("bond of %d years priced at %.12f, want 1", n, price)
+		}
+	}
+}
+
+func TestBuildUsesEligibleBonds(t *testing.T) {
+	date := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	model := NSS{Beta0: 4.2, Beta1: -2.1, Beta2: 1.5, Beta3: -1.2, Tau1: 2, Tau2: 10}
+	var snapshot []Observation
+	for _, years := range fitTenors {
+		maturity := date.Add(time.Duration(years * 365.25 * 24 * float64(time.Hour)))
+		snapshot = append(snapshot, Observation{IssueType: instrument.BTP, Maturity: maturity, YTM: model.Yield(years)})
+	}
+	// Left out of the fit.
+	snapshot = append(snapshot,
+		Observation{IssueType: instrument.BTPItalia, Maturity: date.AddDate(5, 0, 0), YTM: 9},
+		Observation{IssueType: instrument.BTP, Maturity: date.AddDate(0, 3, 0), YTM: 9},
+		Observation{IssueType: instrument.BTP, Maturity: date.AddDate(8, 0, 0)},
+	)
+
+	c, err := Build(date, snapshot)
+	if err != nil {
+		t.Fatalf("Build: %s", err)
+	}
+	if c.Bonds != len(fitTenors) {
+		t.Errorf("Bonds = %d, want %d", c.Bonds, len(fitTenors))
+	}
+	if c.RMSE > 1e-6 {
+		t.Errorf("RMSE = %g, want 0", c.RMSE)
+	}
+	last := c.Points[len(c.Points)-1]
+	if last.Tenor != 30 {
+		t.Errorf("last point at %g years, want 30", last.Tenor)
+	}
+	for _, p := range c.Points {
+		if whole := p.Tenor >= 1; (p.Zero != nil) != whole {
+			t.Errorf("point at %g years has zero rate %v", p.Tenor, p.Zero)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package curve
+
+import (
+	"errors"
+	"math"
+)
+
+var ErrNotEnoughBonds = errors.New("not enough bonds to fit the curve")
+
+// NSS holds the parameters of a Nelson-Siegel-Svensson curve. Yields are in
+// percentage and tenors in years. A Nelson-Siegel curve is a NSS one with
+// Beta3 set to zero.
+type NSS struct {
+	Beta0 float64 `json:"Beta0" bson:"Beta0"`
+	Beta1 float64 `json:"Beta1" bson:"Beta1"`
+	Beta2 float64 `json:"Beta2" bson:"Beta2"`
+	Beta3 float64 `json:"Beta3" bson:"Beta3"`
+	Tau1  float64 `json:"Tau1" bson:"Tau1"`
+	Tau2  float64 `json:"Tau2" bson:"Tau2"`
+}
+
+// Loadings of the betas at the tenor.
+func loadings(t float64, tau1 float64, tau2 float64) [4]float64 {
+	if t <= 0 {
+		// Limit for the tenor going to zero.
+		return [4]float64{1, 1, 0, 0}
+	}
+	x1, x2 := t/tau1, t/tau2
+	slope1 := (1 - math.Exp(-x1)) / x1
+	slope2 := (1 - math.Exp(-x2)) / x2
+	return [4]float64{1, slope1, slope1 - math.Exp(-x1), slope2 - math.Exp(-x2)}
+}
+
+// Yield returns the yield of the curve at the tenor.
+func (c NSS) Yield(t float64) float64 {
+	l := loadings(t, c.Tau1, c.Tau2)
+	return c.Beta0*l[0] + c.Beta1*l[1] + c.Beta2*l[2] + c.Beta3*l[3]
+}
+
+// Solve the n x n linear system a x = b with Gaussian elimination and partial
+// pivoting. Returns false if the system is singular.
+func solve(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, true
+}
+
+// Least squares fit of the betas for fixed taus. `params` is 3 for
+// Nelson-Siegel and 4 for Svensson. Returns the sum of the squared residuals.
+func fitBetas(tenors []float64, yields []float64, tau1 float64, tau2 float64, params int) (NSS, float64, bool) {
+	a := make([][]float64, params)
+	for i := range a {
+		a[i] = make([]float64, params)
+	}
+	b := make([]float64, params)
+	for i, t := range tenors {
+		l := loadings(t, tau1, tau2)
+		for j := 0; j < params; j++ {
+			for k := 0; k < params; k++ {
+				a[j][k] += l[j] * l[k]
+			}
+			b[j] += l[j] * yields[i]
+		}
+	}
+	betas, ok := solve(a, b)
+	if !ok {
+		return NSS{}, 0, false
+	}
+
+	c := NSS{Beta0: betas[0], Beta1: betas[1], Beta2: betas[2], Tau1: tau1, Tau2: tau2}
+	if params == 4 {
+		c.Beta3 = betas[3]
+	}
+	sse := 0.0
+	for i, t := range tenors {
+		r := yields[i] - c.Yield(t)
+		sse += r * r
+	}
+	return c, sse, true
+}
+
+// Candidate decay factors, in years.
+var taus = []float64{0.25, 0.5, 0.75, 1, 1.5, 2, 2.5, 3, 4, 5, 6, 8, 10, 12, 15, 20, 25, 30}
+
+// Fewest bonds needed to fit a Svensson curve. With fewer bonds (but at least
+// four) a Nelson-Siegel curve is fitted instead.
+const minSvenssonBonds = 8
+
+// FitNSS fits the curve to the yields of the bonds by tenor. The decay factors
+// are searched on a grid, while the betas of each pair of decay factors are
+// the ordinary least squares solution, which is linear in them. Returns the
+// curve and its root mean squared error.
+func FitNSS(tenors []float64, yields []float64) (NSS, float64, error) {
+	if len(tenors) < 4 {
+		return NSS{}, 0, ErrNotEnoughBonds
+	}
+	params := 4
+	if len(tenors) < minSvenssonBonds {
+		params = 3
+	}
+
+	var best NSS
+	bestSSE := math.Inf(1)
+	for i, tau1 := range taus {
+		tau2s := taus[i+1:]
+		if params == 3 {
+			// The second decay factor is unused.
+			tau2s = []float64{tau1 * 2}
+		}
+		for _, tau2 := range tau2s {
+			c, sse, ok := fitBetas(tenors, yields, tau1, tau2, params)
+			if ok && sse < bestSSE {
+				best, bestSSE = c, sse
+			}
+		}
+	}
+	if math.IsInf(bestSSE, 1) {
+		return NSS{}, 0, ErrNotEnoughBonds
+	}
+	return best, math.Sqrt(bestSSE / float64(len(tenors))), nil
+}
@@ -0,0 +1,77 @@
+package curve
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var fitTenors = []float64{0.5, 1, 2, 3, 5, 7, 10, 15, 20, 30}
+
+// Yields of the model at the tenors.
+func yieldsOf(model NSS, tenors []float64) []float64 {
+	yields := make([]float64, len(tenors))
+	for i, t := range tenors {
+		yields[i] = model.Yield(t)
+	}
+	return yields
+}
+
+func TestFitNSSRecoversParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  NSS
+		tenors []float64
+	}{
+		{
+			name:   "Svensson",
+			model:  NSS{Beta0: 4.2, Beta1: -2.1, Beta2: 1.5, Beta3: -1.2, Tau1: 2, Tau2: 10},
+			tenors: fitTenors,
+		},
+		{
+			// Fewer than minSvenssonBonds: a Nelson-Siegel curve, whose second
+			// decay factor is unused.
+			name:   "Nelson-Siegel",
+			model:  NSS{Beta0: 3.8, Beta1: -1.4, Beta2: 2.2, Tau1: 1.5, Tau2: 3},
+			tenors: []float64{1, 3, 5, 10, 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rmse, err := FitNSS(tt.tenors, yieldsOf(tt.model, tt.tenors))
+			if err != nil {
+				t.Fatalf("FitNSS: %s", err)
+			}
+			if rmse > 1e-9 {
+				t.Errorf("RMSE = %g, want 0", rmse)
+			}
+			params := [][2]float64{
+				{got.Beta0, tt.model.Beta0}, {got.Beta1, tt.model.Beta1}, {got.Beta2, tt.model.Beta2},
+				{got.Beta3, tt.model.Beta3}, {got.Tau1, tt.model.Tau1}, {got.Tau2, tt.model.Tau2},
+			}
+			for _, p := range params {
+				if math.Abs(p[0]-p[1]) > 1e-6 {
+					t.Errorf("FitNSS = %+v, want %+v", got, tt.model)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFitNSSNotEnoughBonds(t *testing.T) {
+	if _, _, err := FitNSS([]float64{1, 5, 10}, []float64{2, 3, 3.5}); !errors.Is(err, ErrNotEnoughBonds) {
+		t.Errorf("FitNSS of three bonds: %v, want ErrNotEnoughBonds", err)
+	}
+}
+
+func TestYieldAtZeroTenor(t *testing.T) {
+	model := NSS{Beta0: 4, Beta1: -2, Beta2: 1, Beta3: 0.5, Tau1: 2, Tau2: 8}
+	// The short end of the curve tends to Beta0 + Beta1.
+	if got := model.Yield(0); got != 2 {
+		t.Errorf("Yield(0) = %g, want 2", got)
+	}
+	if got := model.Yield(1e-9); math.Abs(got-2) > 1e-6 {
+		t.Errorf("Yield(1e-9) = %g, want about 2", got)
+	}
+}
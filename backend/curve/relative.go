@@ -0,0 +1,68 @@
+package curve
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Spread returns the spread of the bond over the curve, in basis points:
+// positive when the bond yields more than the curve (cheap), negative when it
+// yields less (rich).
+func (c *Curve) Spread(obs Observation) float64 {
+	return (obs.YTM - c.Model.Yield(YearsTo(c.Date, obs.Maturity))) * 100
+}
+
+// SpreadStats summarises the spreads over the curve of a bond in a window.
+type SpreadStats struct {
+	ISIN string `bson:"_id"`
+	// Latest spread and when it was observed.
+	Spread float64   `bson:"Spread"`
+	Date   time.Time `bson:"Date"`
+	// Statistics of all the spreads in the window, the latest included.
+	Mean         float64 `bson:"Mean"`
+	StdDev       float64 `bson:"StdDev"`
+	Observations int     `bson:"Observations"`
+}
+
+// RelativeValue of a bond: how far its current spread is from its usual one.
+type RelativeValue struct {
+	ISIN         string  `json:"ISIN"`
+	Spread       float64 `json:"Spread"`
+	Mean         float64 `json:"Mean"`
+	StdDev       float64 `json:"StdDev"`
+	Observations int     `json:"Observations"`
+	// Standard deviations the spread is above its mean: the higher, the
+	// cheaper the bond is compared to its history.
+	ZScore float64 `json:"ZScore"`
+}
+
+// Rank the bonds last seen in the latest snapshot by z-score, cheapest first.
+// Bonds without enough history to tell are left out.
+func Rank(stats []SpreadStats) []RelativeValue {
+	var latest time.Time
+	for _, s := range stats {
+		if s.Date.After(latest) {
+			latest = s.Date
+		}
+	}
+
+	ranked := []RelativeValue{}
+	for _, s := range stats {
+		if !s.Date.Equal(latest) || s.Observations < 2 || s.StdDev == 0 || math.IsNaN(s.StdDev) {
+			continue
+		}
+		ranked = append(ranked, RelativeValue{
+			ISIN:         s.ISIN,
+			Spread:       s.Spread,
+			Mean:         s.Mean,
+			StdDev:       s.StdDev,
+			Observations: s.Observations,
+			ZScore:       (s.Spread - s.Mean) / s.StdDev,
+		})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].ZScore > ranked[j].ZScore
+	})
+	return ranked
+}
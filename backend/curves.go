@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/store"
+)
+
+// Latest fitted yield curve.
+//
+// This route accepts the following query parameters:
+//   - 'date': as YYYY-MM-DD, to get the last curve fitted on that day instead
+//     of the latest one.
+func (s *server) getCurve(w http.ResponseWriter, r *http.Request) {
+	// Enable CORS.
+	(w).Header().Set("Access-Control-Allow-Origin", "*")
+
+	before := time.Now()
+	date, err := dateParam(r, "date")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !date.IsZero() {
+		before = date.AddDate(0, 0, 1)
+	}
+
+	c, err := s.scrapes.LatestCurve(before)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "No curve fitted yet", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Error while retrieving the curve: %s", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, c)
+}
+
+// Days of history the spreads are compared with, if not given.
+const relativeValueDays = 30
+
+// BTPs ranked by how cheap they trade compared to their usual spread over the
+// curve.
+//
+// This route accepts the following query parameters:
+//   - 'days': the days of history the current spreads are compared with.
+//     Defaults to 30.
+func (s *server) getRelativeValue(w http.ResponseWriter, r *http.Request) {
+	// Enable CORS.
+	(w).Header().Set("Access-Control-Allow-Origin", "*")
+
+	days := relativeValueDays
+	if d := r.URL.Query().Get("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid days %s", d), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	stats, err := s.quotes.SpreadStats("btp", time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error while retrieving the spreads: %s", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, curve.Rank(stats))
+}
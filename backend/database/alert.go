@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/alerts"
+	"btpTracker/backend/store"
+)
+
+const (
+	alertRulesCollection      = "alert_rules"
+	alertDeliveriesCollection = "alert_deliveries"
+)
+
+var _ store.AlertStore = (*MongoStore)(nil)
+
+func (s *MongoStore) CreateAlertRule(r *alerts.Rule) error {
+	r.ID = primitive.NewObjectID()
+	r.CreatedAt = time.Now()
+	r.LastTriggered = nil
+	_, err := s.db.Collection(alertRulesCollection).InsertOne(context.TODO(), r)
+	return err
+}
+
+func (s *MongoStore) ListAlertRules() ([]alerts.Rule, error) {
+	return findAll[alerts.Rule](s.db.Collection(alertRulesCollection), bson.D{}, bson.D{{Key: "CreatedAt", Value: 1}})
+}
+
+func (s *MongoStore) GetAlertRule(id primitive.ObjectID) (*alerts.Rule, error) {
+	return findOne[alerts.Rule](s.db.Collection(alertRulesCollection), bson.D{{Key: "_id", Value: id}})
+}
+
+// UpdateAlertRule replaces the condition of the rule, keeping the last time it
+// triggered.
+func (s *MongoStore) UpdateAlertRule(r alerts.Rule) error {
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "ISIN", Value: r.ISIN},
+		{Key: "Metric", Value: r.Metric},
+		{Key: "Operator", Value: r.Operator},
+		{Key: "Threshold", Value: r.Threshold},
+		{Key: "Cooldown", Value: r.Cooldown},
+	}}}
+	res, err := s.db.Collection(alertRulesCollection).UpdateByID(context.TODO(), r.ID, update)
+	if err != nil {
+		return err
+	}
+	return matched(res.MatchedCount)
+}
+
+// DeleteAlertRule deletes the rule. Its deliveries are kept.
+func (s *MongoStore) DeleteAlertRule(id primitive.ObjectID) error {
+	res, err := s.db.Collection(alertRulesCollection).DeleteOne(context.TODO(), bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return err
+	}
+	return matched(res.DeletedCount)
+}
+
+// SetAlertTriggered records that the rule triggered at the date, starting its
+// cooldown.
+func (s *MongoStore) SetAlertTriggered(id primitive.ObjectID, date time.Time) error {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "LastTriggered", Value: date}}}}
+	_, err := s.db.Collection(alertRulesCollection).UpdateByID(context.TODO(), id, update)
+	return err
+}
+
+func (s *MongoStore) InsertAlertDelivery(d *alerts.Delivery) error {
+	d.ID = primitive.NewObjectID()
+	_, err := s.db.Collection(alertDeliveriesCollection).InsertOne(context.TODO(), d)
+	return err
+}
+
+// ListAlertDeliveries returns the deliveries of the notifications of the rule,
+// most recent first.
+func (s *MongoStore) ListAlertDeliveries(ruleID primitive.ObjectID) ([]alerts.Delivery, error) {
+	return findAll[alerts.Delivery](s.db.Collection(alertDeliveriesCollection), bson.D{{Key: "Notification.RuleID", Value: ruleID}}, bson.D{{Key: "Date", Value: -1}})
+}
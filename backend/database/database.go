@@ -2,12 +2,10 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"os"
 	"time"
 
-	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -33,11 +31,6 @@ type DbRow struct {
 
 func Established_connection() (*mongo.Client, error) {
 
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
-	}
-	log.Println("Loaded the .env file")
-
 	uri := os.Getenv("MONGODB_URI")
 	username := os.Getenv("MONGO_INITDB_ROOT_USERNAME")
 	password := os.Getenv("MONGO_INITDB_ROOT_PASSWORD")
@@ -97,105 +90,8 @@ type TableRow struct {
 	Expiration  string `json:"Expiration" bson:"Expiration"`
 }
 
-func GetBtpHistory(id string) ([]bson.M, error) {
-	// Your MongoDB database and collection names
-	// databaseName := "yourDatabase"
-	collectionName := "btp"
-
-	collection := Database.Collection(collectionName)
-	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "ISIN", Value: id}}}}
-
-	// Print the matchStage
-	// fmt.Println(matchStage)
-
-	// sortStage := bson.D{{Key: "$sort", Value: bson.D{{Key: "$natural", Value: 1}}}}
-	// addFieldsStage := bson.D{{Key: "$addFields", Value: bson.D{
-	// 	{Key: "insertionDate", Value: bson.D{
-	// 		{Key: "$toDate", Value: bson.D{
-	// 			{Key: "$multiply", Value: bson.A{
-	// 				bson.D{{Key: "$toLong", Value: "$_id"}},
-	// 				1000,
-	// 			}},
-	// 		}},
-	// 	}},
-	// }}}
-	projectStage := bson.D{{Key: "$project", Value: bson.D{
-		{Key: "_id", Value: 0},
-		{Key: "name", Value: "$InsertionDate"},
-		{Key: "value", Value: "$Cedola"},
-		// Add more fields as needed
-	}}}
-
-	// Aggregation pipeline
-	pipeline := mongo.Pipeline{matchStage, projectStage}
-
-	cursor, err := collection.Aggregate(context.TODO(), pipeline)
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	var results []bson.M
-	if err := cursor.All(context.TODO(), &results); err != nil {
-		return nil, err
-	}
-	// return results
-	for _, result := range results {
-		fmt.Println(result)
-	}
-	return results, nil
-}
-func GetBotHistory(id string) ([]bson.M, error) {
-	// Your MongoDB database and collection names
-	// databaseName := "yourDatabase"
-	collectionName := "bot"
-
-	collection := Database.Collection(collectionName)
-	matchStage := bson.D{{Key: "$match", Value: bson.D{{Key: "ISIN", Value: id}}}}
-
-	// Print the matchStage
-	// fmt.Println(matchStage)
-
-	// sortStage := bson.D{{Key: "$sort", Value: bson.D{{Key: "$natural", Value: 1}}}}
-	// addFieldsStage := bson.D{{Key: "$addFields", Value: bson.D{
-	// 	{Key: "insertionDate", Value: bson.D{
-	// 		{Key: "$toDate", Value: bson.D{
-	// 			{Key: "$multiply", Value: bson.A{
-	// 				bson.D{{Key: "$toLong", Value: "$_id"}},
-	// 				1000,
-	// 			}},
-	// 		}},
-	// 	}},
-	// }}}
-	projectStage := bson.D{{Key: "$project", Value: bson.D{
-		{Key: "_id", Value: 0},
-		{Key: "name", Value: "$InsertionDate"},
-		{Key: "value", Value: "$Last"},
-		// Add more fields as needed
-	}}}
-
-	// Aggregation pipeline
-	pipeline := mongo.Pipeline{matchStage, projectStage}
-
-	cursor, err := collection.Aggregate(context.TODO(), pipeline)
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	var results []bson.M
-	if err := cursor.All(context.TODO(), &results); err != nil {
-		return nil, err
-	}
-	// return results
-	for _, result := range results {
-		fmt.Println(result)
-	}
-	return results, nil
-}
 func Insert_element(collectionName string, got any) error {
 	collection := Database.Collection(collectionName)
-	log.Println((got))
 	_, err := collection.InsertOne(context.TODO(), got)
 	return err
 }
@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"btpTracker/backend/portfolio"
+	"btpTracker/backend/store"
+)
+
+const (
+	portfoliosCollection = "portfolios"
+	positionsCollection  = "positions"
+	lotsCollection       = "lots"
+)
+
+var _ store.PortfolioStore = (*MongoStore)(nil)
+
+// CreatePortfolioIndexes makes the ISIN of a position unique in its portfolio,
+// so that concurrent requests cannot open the same position twice.
+func (s *MongoStore) CreatePortfolioIndexes() error {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "PortfolioID", Value: 1}, {Key: "ISIN", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := s.db.Collection(positionsCollection).Indexes().CreateOne(context.TODO(), index)
+	return err
+}
+
+// Decode all the documents of the collection matching the filter, sorted by
+// `sort`.
+func findAll[T any](collection *mongo.Collection, filter bson.D, sort bson.D) ([]T, error) {
+	cursor, err := collection.Find(context.TODO(), filter, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	results := []T{}
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Decode the document of the collection matching the filter, or return
+// store.ErrNotFound.
+func findOne[T any](collection *mongo.Collection, filter bson.D) (*T, error) {
+	resp := collection.FindOne(context.TODO(), filter)
+	if err := resp.Err(); err != nil {
+		return nil, notFound(err)
+	}
+	result := new(T)
+	if err := resp.Decode(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Turn an update or a delete that matched nothing into store.ErrNotFound.
+func matched(count int64) error {
+	if count == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) CreatePortfolio(p *portfolio.Portfolio) error {
+	p.ID = primitive.NewObjectID()
+	p.CreatedAt = time.Now()
+	_, err := s.db.Collection(portfoliosCollection).InsertOne(context.TODO(), p)
+	return err
+}
+
+func (s *MongoStore) ListPortfolios() ([]portfolio.Portfolio, error) {
+	return findAll[portfolio.Portfolio](s.db.Collection(portfoliosCollection), bson.D{}, bson.D{{Key: "CreatedAt", Value: 1}})
+}
+
+func (s *MongoStore) GetPortfolio(id primitive.ObjectID) (*portfolio.Portfolio, error) {
+	return findOne[portfolio.Portfolio](s.db.Collection(portfoliosCollection), bson.D{{Key: "_id", Value: id}})
+}
+
+// UpdatePortfolio replaces the name and the description of the portfolio.
+func (s *MongoStore) UpdatePortfolio(p portfolio.Portfolio) error {
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "Name", Value: p.Name},
+		{Key: "Description", Value: p.Description},
+	}}}
+	res, err := s.db.Collection(portfoliosCollection).UpdateByID(context.TODO(), p.ID, update)
+	if err != nil {
+		return err
+	}
+	return matched(res.MatchedCount)
+}
+
+// DeletePortfolio deletes the portfolio with all its positions and lots.
+func (s *MongoStore) DeletePortfolio(id primitive.ObjectID) error {
+	res, err := s.db.Collection(portfoliosCollection).DeleteOne(context.TODO(), bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return err
+	}
+	if err := matched(res.DeletedCount); err != nil {
+		return err
+	}
+	filter := bson.D{{Key: "PortfolioID", Value: id}}
+	if _, err := s.db.Collection(positionsCollection).DeleteMany(context.TODO(), filter); err != nil {
+		return err
+	}
+	_, err = s.db.Collection(lotsCollection).DeleteMany(context.TODO(), filter)
+	return err
+}
+
+// CreatePosition opens the position, unless the portfolio already has one in
+// the same ISIN. The unique index of CreatePortfolioIndexes rejects it then.
+func (s *MongoStore) CreatePosition(p *portfolio.Position) error {
+	p.ID = primitive.NewObjectID()
+	p.CreatedAt = time.Now()
+	_, err := s.db.Collection(positionsCollection).InsertOne(context.TODO(), p)
+	if mongo.IsDuplicateKeyError(err) {
+		return store.ErrDuplicatePosition
+	}
+	return err
+}
+
+func (s *MongoStore) ListPositions(portfolioID primitive.ObjectID) ([]portfolio.Position, error) {
+	return findAll[portfolio.Position](s.db.Collection(positionsCollection), bson.D{{Key: "PortfolioID", Value: portfolioID}}, bson.D{{Key: "CreatedAt", Value: 1}})
+}
+
+func (s *MongoStore) GetPosition(portfolioID primitive.ObjectID, id primitive.ObjectID) (*portfolio.Position, error) {
+	return findOne[portfolio.Position](s.db.Collection(positionsCollection), bson.D{
+		{Key: "_id", Value: id},
+		{Key: "PortfolioID", Value: portfolioID},
+	})
+}
+
+// DeletePosition deletes the position with all its lots.
+func (s *MongoStore) DeletePosition(portfolioID primitive.ObjectID, id primitive.ObjectID) error {
+	res, err := s.db.Collection(positionsCollection).DeleteOne(context.TODO(), bson.D{
+		{Key: "_id", Value: id},
+		{Key: "PortfolioID", Value: portfolioID},
+	})
+	if err != nil {
+		return err
+	}
+	if err := matched(res.DeletedCount); err != nil {
+		return err
+	}
+	_, err = s.db.Collection(lotsCollection).DeleteMany(context.TODO(), bson.D{{Key: "PositionID", Value: id}})
+	return err
+}
+
+func (s *MongoStore) CreateLot(l *portfolio.Lot) error {
+	l.ID = primitive.NewObjectID()
+	_, err := s.db.Collection(lotsCollection).InsertOne(context.TODO(), l)
+	return err
+}
+
+// ListLots returns the lots of the position, oldest first. Lots of the same
+// day come in the order they were entered.
+func (s *MongoStore) ListLots(positionID primitive.ObjectID) ([]portfolio.Lot, error) {
+	return findAll[portfolio.Lot](s.db.Collection(lotsCollection), bson.D{{Key: "PositionID", Value: positionID}}, bson.D{{Key: "Date", Value: 1}, {Key: "_id", Value: 1}})
+}
+
+// ListPortfolioLots returns the lots of all the positions of the portfolio,
+// oldest first. Lots of the same day come in the order they were entered.
+func (s *MongoStore) ListPortfolioLots(portfolioID primitive.ObjectID) ([]portfolio.Lot, error) {
+	return findAll[portfolio.Lot](s.db.Collection(lotsCollection), bson.D{{Key: "PortfolioID", Value: portfolioID}}, bson.D{{Key: "Date", Value: 1}, {Key: "_id", Value: 1}})
+}
+
+// UpdateLot replaces the trade data of the lot.
+func (s *MongoStore) UpdateLot(l portfolio.Lot) error {
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "Side", Value: l.Side},
+		{Key: "Nominal", Value: l.Nominal},
+		{Key: "Price", Value: l.Price},
+		{Key: "Date", Value: l.Date},
+		{Key: "Fees", Value: l.Fees},
+	}}}
+	res, err := s.db.Collection(lotsCollection).UpdateOne(context.TODO(), bson.D{
+		{Key: "_id", Value: l.ID},
+		{Key: "PositionID", Value: l.PositionID},
+	}, update)
+	if err != nil {
+		return err
+	}
+	return matched(res.MatchedCount)
+}
+
+func (s *MongoStore) DeleteLot(positionID primitive.ObjectID, id primitive.ObjectID) error {
+	res, err := s.db.Collection(lotsCollection).DeleteOne(context.TODO(), bson.D{
+		{Key: "_id", Value: id},
+		{Key: "PositionID", Value: positionID},
+	})
+	if err != nil {
+		return err
+	}
+	return matched(res.DeletedCount)
+}
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/instrument"
+	"btpTracker/backend/store"
+)
+
+const instrumentsCollection = "instruments"
+
+// MongoStore keeps the quotes in a collection per source and the instruments
+// in the `instruments` collection. It also keeps the portfolios and the alert
+// rules, which have no other store.
+type MongoStore struct {
+	db *mongo.Database
+}
+
+var _ store.QuoteStore = (*MongoStore)(nil)
+
+func NewMongoStore(db *mongo.Database) *MongoStore {
+	return &MongoStore{db: db}
+}
+
+// OpenMongoStore returns the store of the database, creating the indexes the
+// queries of the store need: by ISIN and date, and by date alone for the
+// latest snapshot, on the collection of each source, and by date on the
+// curves. Creating an index that already exists does nothing.
+func OpenMongoStore(db *mongo.Database, sources []string) (*MongoStore, error) {
+	for _, source := range sources {
+		collection := db.Collection(source)
+		history := mongo.IndexModel{Keys: bson.D{{Key: "ISIN", Value: 1}, {Key: "InsertionDate", Value: 1}}}
+		if _, err := collection.Indexes().CreateOne(context.TODO(), history); err != nil {
+			return nil, err
+		}
+		if err := Create_index(collection, "InsertionDate"); err != nil {
+			return nil, err
+		}
+	}
+	if err := Create_index(db.Collection(curvesCollection), "Date"); err != nil {
+		return nil, err
+	}
+	return NewMongoStore(db), nil
+}
+
+// Turn the missing documents into store.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return store.ErrNotFound
+	}
+	return err
+}
+
+// The documents stored before the quotes were typed hold the price as text in
+// `Last` and have no `Price`: they are left out of the queries, instead of
+// being read as quotes at 0.
+var priced = bson.E{Key: "Price", Value: bson.D{{Key: "$exists", Value: true}}}
+
+// Filter of the InsertionDate in [from, to), open at the zero ends.
+func dateRange(from time.Time, to time.Time) bson.D {
+	var bounds bson.D
+	if !from.IsZero() {
+		bounds = append(bounds, bson.E{Key: "$gte", Value: from})
+	}
+	if !to.IsZero() {
+		bounds = append(bounds, bson.E{Key: "$lt", Value: to})
+	}
+	return bounds
+}
+
+// The rows are inserted in a single unordered bulk write: a row that cannot be
+// inserted does not stop the others.
+func (s *MongoStore) InsertSnapshot(source string, rows []store.Row) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	docs := make([]any, len(rows))
+	for i, row := range rows {
+		docs[i] = row
+	}
+	_, err := s.db.Collection(source).InsertMany(context.TODO(), docs, options.InsertMany().SetOrdered(false))
+	return written(len(docs), err)
+}
+
+// Number of documents of a bulk write that were written and the errors of the
+// others, joined.
+func written(count int, err error) (int, error) {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) {
+		if err != nil {
+			return 0, err
+		}
+		return count, nil
+	}
+	errs := make([]error, len(bulkErr.WriteErrors))
+	for i, writeErr := range bulkErr.WriteErrors {
+		errs[i] = writeErr
+	}
+	if bulkErr.WriteConcernError != nil {
+		errs = append(errs, bulkErr.WriteConcernError)
+	}
+	return count - len(bulkErr.WriteErrors), errors.Join(errs...)
+}
+
+func (s *MongoStore) History(source string, isin string, from time.Time, to time.Time) ([]store.Row, error) {
+	filter := bson.D{{Key: "ISIN", Value: isin}, priced}
+	if bounds := dateRange(from, to); len(bounds) > 0 {
+		filter = append(filter, bson.E{Key: "InsertionDate", Value: bounds})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "InsertionDate", Value: 1}})
+	cursor, err := s.db.Collection(source).Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	rows := []store.Row{}
+	if err := cursor.All(context.TODO(), &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (s *MongoStore) Latest(source string, isin string, before time.Time) (*store.Row, error) {
+	filter := bson.D{
+		{Key: "ISIN", Value: isin},
+		{Key: "InsertionDate", Value: bson.D{{Key: "$lt", Value: before}}},
+		priced,
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "InsertionDate", Value: -1}})
+	resp := s.db.Collection(source).FindOne(context.TODO(), filter, opts)
+	if err := resp.Err(); err != nil {
+		return nil, notFound(err)
+	}
+
+	row := &store.Row{}
+	if err := resp.Decode(row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (s *MongoStore) LatestSnapshot(source string) ([]store.Row, error) {
+	collection := s.db.Collection(source)
+	opts := options.FindOne().SetSort(bson.D{{Key: "InsertionDate", Value: -1}})
+	resp := collection.FindOne(context.TODO(), bson.D{priced}, opts)
+	if err := resp.Err(); err != nil {
+		return nil, notFound(err)
+	}
+	var latest store.Row
+	if err := resp.Decode(&latest); err != nil {
+		return nil, err
+	}
+
+	cursor, err := collection.Find(context.TODO(), bson.D{{Key: "InsertionDate", Value: latest.InsertionDate}, priced})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	rows := []store.Row{}
+	if err := cursor.All(context.TODO(), &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (s *MongoStore) SpreadStats(source string, since time.Time) ([]curve.SpreadStats, error) {
+	collection := s.db.Collection(source)
+	matchStage := bson.D{{Key: "$match", Value: bson.D{
+		{Key: "SpreadToCurve", Value: bson.D{{Key: "$exists", Value: true}}},
+		{Key: "InsertionDate", Value: bson.D{{Key: "$gte", Value: since}}},
+	}}}
+	sortStage := bson.D{{Key: "$sort", Value: bson.D{{Key: "InsertionDate", Value: 1}}}}
+	groupStage := bson.D{{Key: "$group", Value: bson.D{
+		{Key: "_id", Value: "$ISIN"},
+		{Key: "Spread", Value: bson.D{{Key: "$last", Value: "$SpreadToCurve"}}},
+		{Key: "Date", Value: bson.D{{Key: "$last", Value: "$InsertionDate"}}},
+		{Key: "Mean", Value: bson.D{{Key: "$avg", Value: "$SpreadToCurve"}}},
+		{Key: "StdDev", Value: bson.D{{Key: "$stdDevPop", Value: "$SpreadToCurve"}}},
+		{Key: "Observations", Value: bson.D{{Key: "$sum", Value: 1}}},
+	}}}
+
+	pipeline := mongo.Pipeline{matchStage, sortStage, groupStage}
+
+	cursor, err := collection.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	var results []curve.SpreadStats
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// The instruments are upserted in a single unordered bulk write.
+func (s *MongoStore) UpsertInstruments(insts []instrument.Instrument) error {
+	if len(insts) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, len(insts))
+	for i, inst := range insts {
+		update := bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "Description", Value: inst.Description},
+				{Key: "Coupon", Value: inst.Coupon},
+				{Key: "CouponFrequency", Value: inst.CouponFrequency},
+				{Key: "Maturity", Value: inst.Maturity},
+				{Key: "IssueType", Value: inst.IssueType},
+				{Key: "Source", Value: inst.Source},
+				{Key: "LastSeen", Value: inst.LastSeen},
+			}},
+			{Key: "$setOnInsert", Value: bson.D{{Key: "FirstSeen", Value: inst.FirstSeen}}},
+		}
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: "_id", Value: inst.ISIN}}).
+			SetUpdate(update).
+			SetUpsert(true)
+	}
+	_, err := s.db.Collection(instrumentsCollection).BulkWrite(context.TODO(), models, options.BulkWrite().SetOrdered(false))
+	_, err = written(len(models), err)
+	return err
+}
+
+func (s *MongoStore) Instrument(isin string) (*instrument.Instrument, error) {
+	collection := s.db.Collection(instrumentsCollection)
+	resp := collection.FindOne(context.TODO(), bson.D{{Key: "_id", Value: isin}})
+	if err := resp.Err(); err != nil {
+		return nil, notFound(err)
+	}
+
+	inst := &instrument.Instrument{}
+	if err := resp.Decode(inst); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+func (s *MongoStore) Instruments(isins []string) ([]instrument.Instrument, error) {
+	if len(isins) == 0 {
+		// $in does not accept a missing list.
+		return []instrument.Instrument{}, nil
+	}
+	collection := s.db.Collection(instrumentsCollection)
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: isins}}}}
+	cursor, err := collection.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	instruments := []instrument.Instrument{}
+	if err := cursor.All(context.TODO(), &instruments); err != nil {
+		return nil, err
+	}
+	return instruments, nil
+}
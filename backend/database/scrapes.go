@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/store"
+)
+
+const (
+	curvesCollection       = "curves"
+	rejectedRowsCollection = "rejected_rows"
+	scrapeRunsCollection   = "scrape_runs"
+)
+
+var _ store.ScrapeStore = (*MongoStore)(nil)
+
+func (s *MongoStore) InsertCurve(c curve.Curve) error {
+	_, err := s.db.Collection(curvesCollection).InsertOne(context.TODO(), c)
+	return err
+}
+
+func (s *MongoStore) LatestCurve(before time.Time) (*curve.Curve, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "Date", Value: -1}})
+	filter := bson.D{{Key: "Date", Value: bson.D{{Key: "$lt", Value: before}}}}
+	resp := s.db.Collection(curvesCollection).FindOne(context.TODO(), filter, opts)
+	if err := resp.Err(); err != nil {
+		return nil, notFound(err)
+	}
+
+	c := &curve.Curve{}
+	if err := resp.Decode(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// The rows are inserted in a single unordered bulk write.
+func (s *MongoStore) InsertRejected(rows []store.RejectedRow) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	docs := make([]any, len(rows))
+	for i, row := range rows {
+		docs[i] = row
+	}
+	_, err := s.db.Collection(rejectedRowsCollection).InsertMany(context.TODO(), docs, options.InsertMany().SetOrdered(false))
+	return written(len(docs), err)
+}
+
+func (s *MongoStore) InsertRun(run scraper.Run) error {
+	_, err := s.db.Collection(scrapeRunsCollection).InsertOne(context.TODO(), run)
+	return err
+}
@@ -0,0 +1,76 @@
+// Package ical writes all-day events in the iCalendar format (RFC 5545).
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event taking the whole day of Date.
+type Event struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+}
+
+// Escape the characters that have a meaning in the text values.
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// Longest line allowed, in octets, without the line break.
+const maxLineLength = 75
+
+// Fold the line into lines of at most maxLineLength octets: every line after
+// the first one starts with a space. UTF-8 sequences are never split.
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the length.
+		limit = maxLineLength - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+// Write the events as a calendar named `name`.
+func Write(w io.Writer, name string, events []Event) error {
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//btpTracker//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + escaper.Replace(name),
+	}
+	for _, e := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+e.UID,
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+e.Date.Format("20060102"),
+			"DTEND;VALUE=DATE:"+e.Date.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+escaper.Replace(e.Summary),
+			"DESCRIPTION:"+escaper.Replace(e.Description),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(w, "%s\r\n", fold(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
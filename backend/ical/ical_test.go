@@ -0,0 +1,73 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"short", "SUMMARY:Coupon", []string{"SUMMARY:Coupon"}},
+		{"75 octets", strings.Repeat("a", 75), []string{strings.Repeat("a", 75)}},
+		{"76 octets", strings.Repeat("a", 76), []string{strings.Repeat("a", 75), " a"}},
+		{
+			"several lines",
+			strings.Repeat("a", 75+74+10),
+			[]string{strings.Repeat("a", 75), " " + strings.Repeat("a", 74), " " + strings.Repeat("a", 10)},
+		},
+		{
+			// The euro sign takes three octets: it moves to the next line
+			// instead of being split.
+			"multi-octet character",
+			strings.Repeat("a", 73) + "€b",
+			[]string{strings.Repeat("a", 73), " €b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Split(fold(tt.line), "\r\n")
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("fold = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, "BTP", []Event{{
+		UID:         "IT0005240830-20270601@btpTracker",
+		Date:        time.Date(2027, time.June, 1, 0, 0, 0, 0, time.UTC),
+		Summary:     "Btp-1gn27 2,2% coupon",
+		Description: "Coupon of Btp-1gn27 2,2% (IT0005240830): 11.00 EUR gross, 9.63 EUR net of taxes",
+	}})
+	if err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	var unfolded []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+	want := `DESCRIPTION:Coupon of Btp-1gn27 2\,2% (IT0005240830): 11.00 EUR gross\, 9.63 EUR net of taxes`
+	found := false
+	for _, line := range unfolded {
+		found = found || line == want
+	}
+	if !found {
+		t.Errorf("no line %q once unfolded in\n%s", want, buf.String())
+	}
+}
@@ -0,0 +1,97 @@
+package instrument
+
+import (
+	"strings"
+	"time"
+)
+
+// IssueType is the kind of Italian government bond.
+type IssueType string
+
+const (
+	BTP       IssueType = "BTP"
+	BTPItalia IssueType = "BTP Italia"
+	BTPValore IssueType = "BTP Valore"
+	BTPFutura IssueType = "BTP Futura"
+	BTPi      IssueType = "BTP€i"
+	BOT       IssueType = "BOT"
+	CCT       IssueType = "CCT"
+	CTZ       IssueType = "CTZ"
+)
+
+// Prefixes of the descriptions published by Borsa Italiana, e.g.
+// "Btp-1gn27 2,2%" or "Bot Z 13nv26 A". Longer prefixes come first, as "btp"
+// is a prefix of most of them.
+var issuePrefixes = []struct {
+	prefix string
+	issue  IssueType
+}{
+	{"btp italia", BTPItalia},
+	{"btp valore", BTPValore},
+	{"btp futura", BTPFutura},
+	{"btpi", BTPi},
+	{"btp", BTP},
+	{"bot", BOT},
+	{"cct", CCT},
+	{"ctz", CTZ},
+}
+
+// Classify returns the issue type from the description of the bond. When the
+// description is not recognized, the issue type is the name of the list the
+// bond was found in.
+func Classify(source string, description string) IssueType {
+	description = strings.ToLower(strings.TrimSpace(description))
+	for _, p := range issuePrefixes {
+		if strings.HasPrefix(description, p.prefix) {
+			return p.issue
+		}
+	}
+	return IssueType(strings.ToUpper(source))
+}
+
+// CouponFrequency returns how many coupons per year the issue type pays.
+func CouponFrequency(issue IssueType) int {
+	switch issue {
+	case BOT, CTZ:
+		// Zero coupon.
+		return 0
+	case BTPValore:
+		return 4
+	default:
+		return 2
+	}
+}
+
+// Instrument holds the static data of a bond, as stored in the `instruments`
+// collection.
+type Instrument struct {
+	ISIN        string `json:"ISIN" bson:"_id"`
+	Description string `json:"Description" bson:"Description"`
+	// Annual coupon rate, in percentage.
+	Coupon float64 `json:"Coupon" bson:"Coupon"`
+	// Coupons paid per year.
+	CouponFrequency int       `json:"CouponFrequency" bson:"CouponFrequency"`
+	Maturity        time.Time `json:"Maturity" bson:"Maturity"`
+	IssueType       IssueType `json:"IssueType" bson:"IssueType"`
+	// List the instrument is quoted in, which is also the collection of its
+	// quotes.
+	Source    string    `json:"Source" bson:"Source"`
+	FirstSeen time.Time `json:"FirstSeen" bson:"FirstSeen"`
+	LastSeen  time.Time `json:"LastSeen" bson:"LastSeen"`
+}
+
+// New returns the instrument seen at `seen` in the list `source`.
+func New(source string, isin string, description string, coupon float64, maturity time.Time, seen time.Time) Instrument {
+	issue := Classify(source, description)
+	return Instrument{
+		ISIN:            isin,
+		Description:     description,
+		Coupon:          coupon,
+		CouponFrequency: CouponFrequency(issue),
+		Maturity:        maturity,
+		IssueType:       issue,
+		Source:          source,
+		FirstSeen:       seen,
+		LastSeen:        seen,
+	}
+}
@@ -0,0 +1,58 @@
+package instrument
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrISINFormat   = errors.New("an ISIN is a 2 letters country code, 9 alphanumeric characters and a check digit")
+	ErrISINChecksum = errors.New("wrong ISIN check digit")
+)
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// ValidateISIN checks the format of the ISIN and its check digit, computed with
+// the Luhn algorithm over the ISIN with its letters converted to numbers
+// (A = 10, ..., Z = 35).
+func ValidateISIN(isin string) error {
+	if len(isin) != 12 || !isLetter(isin[0]) || !isLetter(isin[1]) || !isDigit(isin[11]) {
+		return ErrISINFormat
+	}
+	var digits strings.Builder
+	for i := 0; i < len(isin); i++ {
+		switch c := isin[i]; {
+		case isLetter(c):
+			digits.WriteString(strconv.Itoa(int(c-'A') + 10))
+		case isDigit(c):
+			digits.WriteByte(c)
+		default:
+			return ErrISINFormat
+		}
+	}
+
+	// Double every other digit starting from the one before the check digit.
+	s := digits.String()
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if (len(s)-1-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	if sum%10 != 0 {
+		return ErrISINChecksum
+	}
+	return nil
+}
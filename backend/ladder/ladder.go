@@ -0,0 +1,210 @@
+// Package ladder builds ladders of bonds: a budget split across bonds maturing
+// at regular intervals up to a horizon, so that part of it is paid back at the
+// end of each interval.
+package ladder
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"btpTracker/backend/analytics"
+	"btpTracker/backend/calendar"
+	"btpTracker/backend/instrument"
+)
+
+var (
+	ErrNoBudget  = errors.New("budget must be positive")
+	ErrNoHorizon = errors.New("horizon must be positive")
+	ErrNoSpacing = errors.New("spacing must be positive and not longer than the horizon")
+)
+
+// Bonds on the MOT are traded in multiples of this nominal.
+const LotSize = 1000.0
+
+// Candidate is a bond of the snapshot the rungs are picked from.
+type Candidate struct {
+	instrument.Instrument
+	// Clean price and yields to maturity, in percentage.
+	Price  float64
+	YTM    float64
+	NetYTM float64
+}
+
+// Request of a ladder.
+type Request struct {
+	Budget float64
+	// Horizon of the ladder and spacing of its rungs, in months.
+	Horizon int
+	Spacing int
+	// Lowest gross yield to maturity, in percentage, of the bonds picked.
+	MinYield float64
+	// Highest clean price above par of the bonds picked (0.5 allows prices up
+	// to 100.5). Negative values only allow bonds below par.
+	MaxPremium float64
+}
+
+// Validate checks the request.
+func (r Request) Validate() error {
+	switch {
+	case r.Budget <= 0:
+		return ErrNoBudget
+	case r.Horizon <= 0:
+		return ErrNoHorizon
+	case r.Spacing <= 0 || r.Spacing > r.Horizon:
+		return ErrNoSpacing
+	}
+	return nil
+}
+
+// Rung of the ladder: the bond picked to mature by the Target date.
+type Rung struct {
+	Target time.Time `json:"Target"`
+	// Nil when no bond of the snapshot matures in the interval of the rung.
+	Bond *Pick `json:"Bond"`
+	// Nominal bought and what is paid for it at settlement, accrued interest
+	// included.
+	Nominal float64 `json:"Nominal"`
+	Cost    float64 `json:"Cost"`
+}
+
+// Pick is the bond picked for a rung.
+type Pick struct {
+	ISIN        string               `json:"ISIN"`
+	Description string               `json:"Description"`
+	IssueType   instrument.IssueType `json:"IssueType"`
+	Maturity    time.Time            `json:"Maturity"`
+	Price       float64              `json:"Price"`
+	DirtyPrice  float64              `json:"DirtyPrice"`
+	YTM         float64              `json:"YTM"`
+	NetYTM      float64              `json:"NetYTM"`
+}
+
+// CashFlow paid by the bonds of the ladder on a date.
+type CashFlow struct {
+	Date       time.Time `json:"Date"`
+	Coupon     float64   `json:"Coupon"`
+	Redemption float64   `json:"Redemption"`
+	Amount     float64   `json:"Amount"`
+}
+
+// Ladder proposed for a request.
+type Ladder struct {
+	// Settlement date of the purchases.
+	Date     time.Time `json:"Date"`
+	Budget   float64   `json:"Budget"`
+	Invested float64   `json:"Invested"`
+	Cash     float64   `json:"Cash"`
+	// Yields to maturity of the rungs, weighted by their cost.
+	YTM       float64    `json:"YTM"`
+	NetYTM    float64    `json:"NetYTM"`
+	Rungs     []Rung     `json:"Rungs"`
+	CashFlows []CashFlow `json:"CashFlows"`
+}
+
+// Whether the candidate satisfies the constraints of the request.
+func (r Request) accepts(c Candidate) bool {
+	return c.Price > 0 && c.YTM >= r.MinYield && c.Price <= analytics.Par+r.MaxPremium
+}
+
+// Build picks, for each rung, the bond with the highest yield maturing in the
+// interval ending at the rung among the candidates, and splits the budget
+// evenly across the rungs filled, in lots of LotSize. Bonds are bought on
+// `date` and paid at its settlement date.
+func Build(req Request, candidates []Candidate, date time.Time) (*Ladder, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	settlement := calendar.SettlementDate(date)
+	var picks []*Candidate
+	filled := 0
+	for months := req.Spacing; months <= req.Horizon; months += req.Spacing {
+		// Bonds maturing before the settlement cannot be bought anymore.
+		from, to := date.AddDate(0, months-req.Spacing, 0), date.AddDate(0, months, 0)
+		if from.Before(settlement) {
+			from = settlement
+		}
+		var best *Candidate
+		for i, c := range candidates {
+			if c.Maturity.After(from) && !c.Maturity.After(to) && req.accepts(c) &&
+				(best == nil || c.YTM > best.YTM) {
+				best = &candidates[i]
+			}
+		}
+		if best != nil {
+			filled++
+		}
+		picks = append(picks, best)
+	}
+
+	ladder := &Ladder{
+		Date:      settlement,
+		Budget:    req.Budget,
+		Rungs:     []Rung{},
+		CashFlows: []CashFlow{},
+	}
+	flows := map[time.Time]*CashFlow{}
+	for i, c := range picks {
+		rung := Rung{Target: date.AddDate(0, req.Spacing*(i+1), 0)}
+		if c == nil {
+			ladder.Rungs = append(ladder.Rungs, rung)
+			continue
+		}
+
+		bond := analytics.NewBond(c.Instrument)
+		settled, err := bond.Settle(c.Price, date)
+		if err != nil {
+			return nil, err
+		}
+		rung.Bond = &Pick{
+			ISIN:        c.ISIN,
+			Description: c.Description,
+			IssueType:   c.IssueType,
+			Maturity:    c.Maturity,
+			Price:       c.Price,
+			DirtyPrice:  settled.DirtyPrice,
+			YTM:         c.YTM,
+			NetYTM:      c.NetYTM,
+		}
+		share := req.Budget / float64(filled)
+		rung.Nominal = math.Floor(share/(settled.DirtyPrice/analytics.Par)/LotSize) * LotSize
+		rung.Cost = rung.Nominal * settled.DirtyPrice / analytics.Par
+		ladder.Rungs = append(ladder.Rungs, rung)
+		if rung.Nominal == 0 {
+			continue
+		}
+
+		payments, err := bond.Schedule(settled.SettlementDate, rung.Nominal)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range payments {
+			flow, ok := flows[p.Date]
+			if !ok {
+				flow = &CashFlow{Date: p.Date}
+				flows[p.Date] = flow
+			}
+			flow.Coupon += p.Coupon
+			flow.Redemption += p.Redemption
+			flow.Amount += p.Amount
+		}
+		ladder.Invested += rung.Cost
+		ladder.YTM += rung.Cost * c.YTM
+		ladder.NetYTM += rung.Cost * c.NetYTM
+	}
+
+	ladder.Cash = req.Budget - ladder.Invested
+	if ladder.Invested > 0 {
+		ladder.YTM /= ladder.Invested
+		ladder.NetYTM /= ladder.Invested
+	}
+	for _, flow := range flows {
+		ladder.CashFlows = append(ladder.CashFlows, *flow)
+	}
+	sort.Slice(ladder.CashFlows, func(i, j int) bool {
+		return ladder.CashFlows[i].Date.Before(ladder.CashFlows[j].Date)
+	})
+	return ladder, nil
+}
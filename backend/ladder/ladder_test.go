@@ -0,0 +1,150 @@
+package ladder
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"btpTracker/backend/instrument"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Monday: the purchases settle on Wednesday 21 October.
+var trade = date(2026, time.October, 19)
+
+// Zero coupon bond, whose dirty price is its clean price.
+func bot(isin string, maturity time.Time, price float64, ytm float64) Candidate {
+	return Candidate{
+		Instrument: instrument.Instrument{ISIN: isin, IssueType: instrument.BOT, Maturity: maturity},
+		Price:      price,
+		YTM:        ytm,
+		NetYTM:     ytm * 0.875,
+	}
+}
+
+var candidates = []Candidate{
+	// Matures before the settlement.
+	bot("IT0000000001", date(2026, time.October, 20), 99.99, 9),
+	bot("IT0000000002", date(2027, time.April, 14), 98, 2),
+	bot("IT0000000003", date(2027, time.October, 14), 97, 2.5),
+	bot("IT0000000004", date(2028, time.June, 14), 95, 2.6),
+	// Above par.
+	bot("IT0000000005", date(2028, time.September, 14), 101, 3),
+	// Below the lowest yield.
+	bot("IT0000000006", date(2028, time.August, 14), 90, 1),
+	// Not traded.
+	bot("IT0000000007", date(2028, time.August, 1), 0, 5),
+}
+
+// ISINs of the bonds of the rungs, empty for the rungs without a bond.
+func rungISINs(l *Ladder) []string {
+	isins := make([]string, len(l.Rungs))
+	for i, rung := range l.Rungs {
+		if rung.Bond != nil {
+			isins[i] = rung.Bond.ISIN
+		}
+	}
+	return isins
+}
+
+func TestBuildPicksTheHighestYieldOfEachRung(t *testing.T) {
+	req := Request{Budget: 10000, Horizon: 24, Spacing: 12, MinYield: 1.5, MaxPremium: 0}
+	l, err := Build(req, candidates, trade)
+	if err != nil {
+		t.Fatalf("Build: %s", err)
+	}
+	if !l.Date.Equal(date(2026, time.October, 21)) {
+		t.Errorf("settled on %s, want T+2", l.Date.Format(time.DateOnly))
+	}
+	want := []string{"IT0000000003", "IT0000000004"}
+	if got := rungISINs(l); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("rungs %v, want %v", got, want)
+	}
+
+	// 5000 for each rung, in lots of 1000 of nominal.
+	for i, cost := range []float64{4850, 4750} {
+		rung := l.Rungs[i]
+		if rung.Nominal != 5000 || math.Abs(rung.Cost-cost) > 1e-9 {
+			t.Errorf("rung %d: nominal %g for %g, want 5000 for %g", i, rung.Nominal, rung.Cost, cost)
+		}
+	}
+	if math.Abs(l.Invested-9600) > 1e-9 || math.Abs(l.Cash-400) > 1e-9 {
+		t.Errorf("invested %g with %g of cash left, want 9600 and 400", l.Invested, l.Cash)
+	}
+	ytm := (4850*2.5 + 4750*2.6) / 9600
+	if math.Abs(l.YTM-ytm) > 1e-9 || math.Abs(l.NetYTM-ytm*0.875) > 1e-9 {
+		t.Errorf("YTM %g and net %g, want %g and %g weighted by cost", l.YTM, l.NetYTM, ytm, ytm*0.875)
+	}
+
+	if len(l.CashFlows) != 2 {
+		t.Fatalf("cash flows %+v, want the two redemptions", l.CashFlows)
+	}
+	for i, maturity := range []time.Time{date(2027, time.October, 14), date(2028, time.June, 14)} {
+		flow := l.CashFlows[i]
+		if !flow.Date.Equal(maturity) || flow.Redemption != 5000 || flow.Amount != 5000 {
+			t.Errorf("cash flow %d: %+v, want 5000 redeemed on %s", i, flow, maturity.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestBuildLeavesRungsWithoutBondsEmpty(t *testing.T) {
+	req := Request{Budget: 10000, Horizon: 36, Spacing: 12, MinYield: math.Inf(-1), MaxPremium: math.Inf(1)}
+	l, err := Build(req, candidates, trade)
+	if err != nil {
+		t.Fatalf("Build: %s", err)
+	}
+	want := []string{"IT0000000003", "IT0000000005", ""}
+	got := rungISINs(l)
+	if len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("rungs %v, want %v", got, want)
+	}
+	// The budget is split across the two rungs filled only.
+	if l.Rungs[0].Nominal != 5000 || l.Rungs[1].Nominal != 4000 {
+		t.Errorf("nominals %g and %g, want 5000 and 4000", l.Rungs[0].Nominal, l.Rungs[1].Nominal)
+	}
+	if empty := l.Rungs[2]; empty.Nominal != 0 || empty.Cost != 0 || !empty.Target.Equal(date(2029, time.October, 19)) {
+		t.Errorf("empty rung %+v", empty)
+	}
+}
+
+func TestBuildRoundsDownToLots(t *testing.T) {
+	req := Request{Budget: 1800, Horizon: 24, Spacing: 12, MinYield: 1.5, MaxPremium: 0}
+	l, err := Build(req, candidates, trade)
+	if err != nil {
+		t.Fatalf("Build: %s", err)
+	}
+	// 900 for each rung is less than a lot: nothing is bought.
+	for i, rung := range l.Rungs {
+		if rung.Bond == nil || rung.Nominal != 0 || rung.Cost != 0 {
+			t.Errorf("rung %d: %+v, want the bond and no nominal", i, rung)
+		}
+	}
+	if l.Invested != 0 || l.Cash != 1800 || l.YTM != 0 || len(l.CashFlows) != 0 {
+		t.Errorf("invested %g, cash %g, YTM %g, %d cash flows; want all in cash", l.Invested, l.Cash, l.YTM, len(l.CashFlows))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		req  Request
+		want error
+	}{
+		{Request{Budget: 1000, Horizon: 12, Spacing: 12}, nil},
+		{Request{Budget: 0, Horizon: 12, Spacing: 12}, ErrNoBudget},
+		{Request{Budget: 1000, Horizon: 0, Spacing: 12}, ErrNoHorizon},
+		{Request{Budget: 1000, Horizon: 12, Spacing: 0}, ErrNoSpacing},
+		{Request{Budget: 1000, Horizon: 12, Spacing: 13}, ErrNoSpacing},
+	}
+	for _, tt := range tests {
+		if err := tt.req.Validate(); !errors.Is(err, tt.want) {
+			t.Errorf("Validate(%+v) = %v, want %v", tt.req, err, tt.want)
+		}
+		if _, err := Build(tt.req, candidates, trade); !errors.Is(err, tt.want) {
+			t.Errorf("Build(%+v) = %v, want %v", tt.req, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"btpTracker/backend/ladder"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/store"
+)
+
+// Bonds of the latest snapshot of every source, with their static data.
+func (s *server) latestCandidates() ([]ladder.Candidate, error) {
+	var rows []store.Row
+	for _, src := range scraper.Sources() {
+		sourceRows, err := s.quotes.LatestSnapshot(src.Name())
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+		rows = append(rows, sourceRows...)
+	}
+
+	isins := make([]string, len(rows))
+	for i, row := range rows {
+		isins[i] = row.ISIN
+	}
+	instruments, err := s.quotes.Instruments(isins)
+	if err != nil {
+		return nil, err
+	}
+	quotes := map[string]store.Row{}
+	for _, row := range rows {
+		quotes[row.ISIN] = row
+	}
+
+	candidates := make([]ladder.Candidate, 0, len(instruments))
+	for _, inst := range instruments {
+		row := quotes[inst.ISIN]
+		candidates = append(candidates, ladder.Candidate{
+			Instrument: inst,
+			Price:      row.Price,
+			YTM:        row.YTM,
+			NetYTM:     row.NetYTM,
+		})
+	}
+	return candidates, nil
+}
+
+// Parse the query parameter `name` as a float, or return the fallback if it is
+// missing.
+func floatParam(r *http.Request, name string, fallback float64) (float64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %s", name, value)
+	}
+	return parsed, nil
+}
+
+// Parse the query parameter `name` as an integer, or return the fallback if it
+// is missing.
+func intParam(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %s, expected a whole number", name, value)
+	}
+	return parsed, nil
+}
+
+// Proposes a ladder of the bonds of the latest snapshot, with a rung maturing at
+// the end of each interval of the horizon.
+// This route accepts the following query parameters:
+//   - 'budget': amount to invest, in euros. Required.
+//   - 'horizon': years to the last rung. Defaults to 5.
+//   - 'spacing': months between the rungs. Defaults to 12.
+//   - 'minYield': lowest gross yield to maturity of the bonds, in percentage.
+//   - 'maxPremium': highest clean price above par of the bonds (0.5 allows prices
+//     up to 100.5). No limit by default.
+func (s *server) getLadder(w http.ResponseWriter, r *http.Request) {
+	// Enable CORS.
+	(w).Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	budget, err := floatParam(r, "budget", 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	horizon, err := floatParam(r, "horizon", 5)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	spacing, err := intParam(r, "spacing", 12)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	minYield, err := floatParam(r, "minYield", math.Inf(-1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	maxPremium, err := floatParam(r, "maxPremium", math.Inf(1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := ladder.Request{
+		Budget:     budget,
+		Horizon:    int(math.Round(horizon * 12)),
+		Spacing:    spacing,
+		MinYield:   minYield,
+		MaxPremium: maxPremium,
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	candidates, err := s.latestCandidates()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error while retrieving the latest snapshot: %s", err), http.StatusInternalServerError)
+		return
+	}
+	l, err := ladder.Build(req, candidates, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot build the ladder: %s", err), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, l)
+}
@@ -1,17 +1,28 @@
 package main
 
 import (
+	"btpTracker/backend/alerts"
+	"btpTracker/backend/analytics"
+	"btpTracker/backend/curve"
 	"btpTracker/backend/database"
+	"btpTracker/backend/instrument"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/scraper/replay"
+	"btpTracker/backend/store"
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/joho/godotenv"
 	"github.com/robfig/cron/v3"
-
-	"strings"
-
-	"github.com/gocolly/colly/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	// "encoding/json"
 	// "errors"
@@ -32,21 +43,43 @@ import (
 
 const port string = ":8080"
 
-// TableRow represents the structure of each row in the table
-type TableRow struct {
-	ISIN        string `json:"ISIN" bson:"ISIN"`
-	Description string `json:"Description" bson:"Description"`
-	Last        string `json:"Last" bson:"Last"`
-	Cedola      string `json:"Cedola" bson:"Cedola"`
-	Expiration  string `json:"Expiration" bson:"Expiration"`
+// Taxation used to compute the net yields. Read from the environment at
+// startup.
+var taxation = analytics.DefaultTaxation
+
+var (
+	recordDir = flag.String("record", "", "Record the list pages in the given directory and exit")
+	replayDir = flag.String("replay", "", "Scrape the list pages recorded in the given directory instead of Borsa Italiana")
+)
+
+// server holds what the HTTP handlers and the scheduled scrape depend on.
+type server struct {
+	quotes store.QuoteStore
+	// Curves, rejected rows and scrape runs.
+	scrapes store.ScrapeStore
+	// Kept in MongoDB only: nil when the tracker runs without it.
+	portfolios store.PortfolioStore
+	alerts     store.AlertStore
+	// Channels the notifications of the alerts are delivered to, and the
+	// deliveries still running, waited for on shutdown.
+	notifiers  []alerts.Notifier
+	deliveries sync.WaitGroup
+}
+
+// RTRow is a quote with its analytics, as returned by the real-time endpoints.
+type RTRow struct {
+	scraper.Quote
+	analytics.Metrics
 }
-type DbRow struct {
-	ISIN          string    `json:"ISIN" bson:"ISIN"`
-	Description   string    `json:"Description" bson:"Description"`
-	Last          string    `json:"Last" bson:"Last"`
-	Cedola        string    `json:"Cedola" bson:"Cedola"`
-	Expiration    string    `json:"Expiration" bson:"Expiration"`
-	InsertionDate time.Time `json:"InsertionDate" bson:"InsertionDate"`
+
+// Compute the analytics of the quote found at `date` in the list `source`.
+// Quotes whose analytics cannot be computed are returned without them.
+func computeMetrics(source string, q scraper.Quote, date time.Time) analytics.Metrics {
+	metrics, err := analytics.Compute(q.Instrument(source, date), q.Price, date, taxation)
+	if err != nil {
+		log.Printf("Cannot compute the analytics of %s: %s\n", q.ISIN, err)
+	}
+	return metrics
 }
 
 // func assert(cond bool) {
@@ -346,149 +379,116 @@ var numCPU = runtime.NumCPU() * 2
 //		json_string, err := json.Marshal(trees)
 //		w.Write(json_string)
 //	}
-func retrieveBOTData() []TableRow {
-	log.Println("Start Retrieving")
-	var rows []TableRow
-
-	c := colly.NewCollector()
-	// Set up rules for data extraction
-	c.OnHTML("tr", func(row *colly.HTMLElement) {
-		// Create a new TableRow object for each row
-		tableRow := TableRow{}
-
-		// Extract data from each column (td) in the row
-		row.ForEach("td", func(colIdx int, col *colly.HTMLElement) {
-			cellText := strings.TrimSpace(strings.ReplaceAll(col.Text, "\n", ""))
-
-			switch colIdx {
-			case 0:
-				tableRow.ISIN = strings.Trim(strings.Split(cellText, "-")[0], " ")
-			case 1:
-				tableRow.Description = cellText
-			case 2:
-				tableRow.Last = cellText
-			case 3:
-				tableRow.Cedola = cellText
-			case 4:
-				tableRow.Expiration = cellText
-			}
-		})
 
-		// Append the TableRow object to the slice
-		rows = append(rows, tableRow)
-	})
-
-	// c.OnHTML("p", func(e *colly.HTMLElement) {
-	// 	fmt.Println("Paragraph:", e.Text)
-	// })
+// HistoryPoint is a row of the time series served by the history endpoints.
+type HistoryPoint struct {
+	Name   time.Time `json:"name"`
+	Value  float64   `json:"value"`
+	YTM    float64   `json:"ytm,omitempty"`
+	NetYTM float64   `json:"netYtm,omitempty"`
+	// BTPs only.
+	Spread *float64 `json:"spread,omitempty"`
+	// BOTs only.
+	SimpleYield float64 `json:"simpleYield,omitempty"`
+	Days        int     `json:"days,omitempty"`
+}
 
-	// for i := 1; i <= 7; i++ {
-	// Set the URL to be scraped
-	err := c.Visit("https://www.borsaitaliana.it/borsa/obbligazioni/mot/bot/lista.html?&page=" + fmt.Sprint(1) + "#")
+// Parse the query parameter `name` as a date, or return the zero time if it is
+// missing. The date starts at midnight local time, like the days of the scrapes:
+// every endpoint must agree on which day a quote was stored.
+func dateParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
 	if err != nil {
-		fmt.Println("Error:", err)
+		return time.Time{}, fmt.Errorf("cannot parse %s %s", name, value)
 	}
-	// }
-	log.Println(rows)
-	return rows
+	return parsed, nil
 }
-func retrieveData() []TableRow {
-	log.Println("Start Retrieving")
-	var rows []TableRow
-
-	c := colly.NewCollector()
-	// Set up rules for data extraction
-	c.OnHTML("tr", func(row *colly.HTMLElement) {
-		// Create a new TableRow object for each row
-		tableRow := TableRow{}
-
-		// Extract data from each column (td) in the row
-		row.ForEach("td", func(colIdx int, col *colly.HTMLElement) {
-			cellText := strings.TrimSpace(strings.ReplaceAll(col.Text, "\n", ""))
-
-			switch colIdx {
-			case 0:
-				tableRow.ISIN = strings.Trim(strings.Split(cellText, "-")[0], " ")
-			case 1:
-				tableRow.Description = cellText
-			case 2:
-				tableRow.Last = cellText
-			case 3:
-				tableRow.Cedola = cellText
-			case 4:
-				tableRow.Expiration = cellText
-			}
-		})
-
-		// Append the TableRow object to the slice
-		rows = append(rows, tableRow)
-	})
 
-	// c.OnHTML("p", func(e *colly.HTMLElement) {
-	// 	fmt.Println("Paragraph:", e.Text)
-	// })
-
-	for i := 1; i <= 7; i++ {
-		// Set the URL to be scraped
-		err := c.Visit("https://www.borsaitaliana.it/borsa/obbligazioni/mot/btp/lista.html?&page=" + fmt.Sprint(i) + "#")
-		if err != nil {
-			fmt.Println("Error:", err)
-		}
-	}
-	log.Println(rows)
-	return rows
-}
-func getBTPData(w http.ResponseWriter, r *http.Request) {
-	log.Println("InsideBTP")
+// Write the history of an ISIN stored from the source.
+// This route accepts the following query parameters:
+//   - 'id': the ISIN. Required.
+//   - 'from', 'to': only return the rows stored from the day 'from' and before
+//     the day 'to' (YYYY-MM-DD). The whole history by default.
+func (s *server) writeHistory(w http.ResponseWriter, r *http.Request, source string) {
+	// Enable CORS.
 	(w).Header().Set("Access-Control-Allow-Origin", "*")
 
-	queryValues := r.URL.Query()
-
-	// Get the id of the paper to retrieve.
-	id := queryValues["id"][0]
-	log.Println(id)
-	res, err := database.GetBtpHistory(id)
-	responseJSON, err := json.Marshal(res)
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Missing id", http.StatusBadRequest)
+		return
+	}
+	from, err := dateParam(r, "from")
 	if err != nil {
-		http.Error(w, "Error encoding JSON", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := dateParam(r, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	// Set the Content-Type header to application/json
-	w.Header().Set("Content-Type", "application/json")
-
-	// Write the JSON response
-	w.Write(responseJSON)
-}
-func getBOTData(w http.ResponseWriter, r *http.Request) {
-	log.Println("InsideBTP")
-	(w).Header().Set("Access-Control-Allow-Origin", "*")
-
-	queryValues := r.URL.Query()
 
-	// Get the id of the paper to retrieve.
-	id := queryValues["id"][0]
-	log.Println(id)
-	res, err := database.GetBotHistory(id)
-	responseJSON, err := json.Marshal(res)
+	rows, err := s.quotes.History(source, id, from, to)
 	if err != nil {
-		http.Error(w, "Error encoding JSON", http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Error while retrieving the history of %s: %s", id, err), http.StatusInternalServerError)
 		return
 	}
-	// Set the Content-Type header to application/json
-	w.Header().Set("Content-Type", "application/json")
+	points := make([]HistoryPoint, len(rows))
+	for i, row := range rows {
+		points[i] = HistoryPoint{
+			Name:        row.InsertionDate,
+			Value:       row.Price,
+			YTM:         row.YTM,
+			NetYTM:      row.NetYTM,
+			Spread:      row.SpreadToCurve,
+			SimpleYield: row.SimpleYield,
+			Days:        row.DaysToMaturity,
+		}
+	}
+	writeJSON(w, points)
+}
 
-	// Write the JSON response
-	w.Write(responseJSON)
+func (s *server) getBTPData(w http.ResponseWriter, r *http.Request) {
+	s.writeHistory(w, r, "btp")
 }
-func getRTData(w http.ResponseWriter, r *http.Request) {
+
+func (s *server) getBOTData(w http.ResponseWriter, r *http.Request) {
+	s.writeHistory(w, r, "bot")
+}
+
+// Scrape the source named `name` and write its rows as JSON.
+func writeRTData(w http.ResponseWriter, name string) {
 	// Enable CORS.
 	(w).Header().Set("Access-Control-Allow-Origin", "*")
-	var rows []TableRow
 
-	log.Println("rows")
-	rows = retrieveData()
+	src, ok := scraper.Lookup(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown source %s", name), http.StatusNotFound)
+		return
+	}
+	result, err := scraper.Scrape(src)
+	if result == nil {
+		http.Error(w, fmt.Sprintf("Error while retrieving %s: %s", name, err), http.StatusBadGateway)
+		return
+	}
+	if err != nil {
+		log.Printf("Error while retrieving %s: %s\n", name, err)
+	}
 
-	log.Println(rows)
+	for _, rejected := range result.Rejected {
+		log.Printf("Rejected %s row %v: %s\n", name, rejected.Row, rejected.Reason)
+	}
+
+	now := time.Now()
+	rows := make([]RTRow, len(result.Quotes))
+	for i, q := range result.Quotes {
+		rows[i] = RTRow{Quote: q, Metrics: computeMetrics(name, q, now)}
+	}
 
 	responseJSON, err := json.Marshal(rows)
 	if err != nil {
@@ -502,62 +502,232 @@ func getRTData(w http.ResponseWriter, r *http.Request) {
 	w.Write(responseJSON)
 }
 
+func getRTData(w http.ResponseWriter, r *http.Request) {
+	writeRTData(w, "btp")
+}
+
 func getRTBOTData(w http.ResponseWriter, r *http.Request) {
-	// Enable CORS.
-	(w).Header().Set("Access-Control-Allow-Origin", "*")
-	var rows []TableRow
+	writeRTData(w, "bot")
+}
 
-	log.Println("rows")
-	rows = retrieveBOTData()
+// Scrape all the sources and fit the yield curve to the snapshot, then store
+// the quotes with their spread over the curve.
+func (s *server) scrapeAll() {
+	now := time.Now()
+
+	// Quotes to store, by source, and the bonds they quote.
+	rows := map[string][]store.Row{}
+	descriptions := map[string]string{}
+	runs := map[string]*scraper.Run{}
+	// When the scrape of each source was over.
+	scraped := map[string]time.Time{}
+	var snapshot []curve.Observation
+	for _, src := range scraper.Sources() {
+		run := &scraper.Run{ID: primitive.NewObjectID().Hex(), Source: src.Name(), Start: time.Now()}
+		runs[src.Name()] = run
+		s.scrape(src, run, now, rows, descriptions, &snapshot)
+		scraped[src.Name()] = time.Now()
+	}
+
+	c, err := curve.Build(now, snapshot)
+	if err != nil {
+		log.Printf("Cannot fit the curve: %s\n", err)
+	} else {
+		if err := s.scrapes.InsertCurve(*c); err != nil {
+			fmt.Println("Error:", err)
+		}
+		spreads := map[string]float64{}
+		for _, obs := range snapshot {
+			if obs.IssueType == instrument.BTP && obs.YTM != 0 {
+				spreads[obs.ISIN] = c.Spread(obs)
+			}
+		}
+		for _, sourceRows := range rows {
+			for i := range sourceRows {
+				if spread, ok := spreads[sourceRows[i].ISIN]; ok {
+					sourceRows[i].SpreadToCurve = &spread
+				}
+			}
+		}
+	}
 
-	log.Println(rows)
+	for _, src := range scraper.Sources() {
+		run := runs[src.Name()]
+		elsewhere := time.Since(scraped[src.Name()])
+		if sourceRows := rows[src.Name()]; len(sourceRows) > 0 {
+			stored, err := s.quotes.InsertSnapshot(src.Name(), sourceRows)
+			if err != nil {
+				fmt.Println("Error:", err)
+				run.AddError(err)
+			}
+			run.Stored = stored
+		}
+		run.Finish(time.Now(), elsewhere)
+		log.Printf("Run %s of %s: %d rows in %d pages, %d quotes stored, %d rejected, %d errors in %.1fs\n",
+			run.ID, run.Source, run.Rows, run.Pages, run.Stored, run.Rejected, run.Errors, run.Duration)
+		if err := s.scrapes.InsertRun(*run); err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
 
-	responseJSON, err := json.Marshal(rows)
+	s.evaluateAlerts(rows, descriptions, now)
+}
+
+// Scrape the source, storing the rows it rejected and the instruments it
+// quotes, and add its quotes to the rows to store and to the snapshot the
+// curve is fitted to.
+func (s *server) scrape(src scraper.InstrumentSource, run *scraper.Run, now time.Time,
+	rows map[string][]store.Row, descriptions map[string]string, snapshot *[]curve.Observation) {
+	result, err := scraper.Scrape(src)
 	if err != nil {
-		http.Error(w, "Error encoding JSON", http.StatusInternalServerError)
+		fmt.Println("Error:", err)
+		run.AddError(err)
+	}
+	if result == nil {
 		return
 	}
-	// Set the Content-Type header to application/json
-	w.Header().Set("Content-Type", "application/json")
+	run.Pages = result.Pages
+	run.Rows = len(result.Rows)
+	run.Quotes = len(result.Quotes)
+	run.Rejected = len(result.Rejected)
+
+	if len(result.Rejected) > 0 {
+		rejected := make([]store.RejectedRow, len(result.Rejected))
+		for i, rejection := range result.Rejected {
+			rejected[i] = store.RejectedRow{
+				Source:        src.Name(),
+				Rejection:     rejection,
+				InsertionDate: now,
+				RunID:         run.ID,
+			}
+		}
+		if _, err := s.scrapes.InsertRejected(rejected); err != nil {
+			fmt.Println("Error:", err)
+			run.AddError(err)
+		}
+	}
 
-	// Write the JSON response
-	w.Write(responseJSON)
+	insts := make([]instrument.Instrument, len(result.Quotes))
+	for i, q := range result.Quotes {
+		insts[i] = q.Instrument(src.Name(), now)
+		metrics := computeMetrics(src.Name(), q, now)
+		descriptions[q.ISIN] = q.Description
+		rows[src.Name()] = append(rows[src.Name()], store.Row{
+			ISIN:          q.ISIN,
+			Price:         q.Price,
+			Metrics:       metrics,
+			InsertionDate: now,
+			RunID:         run.ID,
+		})
+		*snapshot = append(*snapshot, curve.Observation{
+			ISIN:      q.ISIN,
+			IssueType: insts[i].IssueType,
+			Maturity:  q.Maturity,
+			YTM:       metrics.YTM,
+		})
+	}
+	if err := s.quotes.UpsertInstruments(insts); err != nil {
+		fmt.Println("Error:", err)
+		run.AddError(err)
+	}
 }
 
 func main() {
+	flag.Parse()
 	log.Printf("Using %d CPUs\n", numCPU)
 
-	// DB Connection
-	var err error
-	database.Client, err = database.Established_connection()
-	if err != nil {
-		panic(err)
+	if *recordDir != "" {
+		for _, src := range scraper.Sources() {
+			if _, err := replay.Record(src, *recordDir); err != nil {
+				log.Fatalf("Error while recording %s: %s", src.Name(), err)
+			}
+		}
+		return
+	}
+	if *replayDir != "" {
+		server := replay.NewServer(*replayDir)
+		defer server.Close()
+		replay.Use(server.URL)
+		log.Printf("Replaying the pages recorded in %s\n", *replayDir)
+	}
+
+	// The .env file configures the storage, the taxation and the alerts.
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
 	}
+	kind := storeKind()
 
-	log.Println("Connection established")
-	defer func() {
-		if err := database.Client.Disconnect(context.TODO()); err != nil {
+	// DB Connection. MongoDB keeps the portfolios and the alerts whichever the
+	// store: without it they are not available.
+	s := &server{}
+	var err error
+	if useMongo(kind) {
+		database.Client, err = database.Established_connection()
+		if err != nil {
 			panic(err)
 		}
-	}()
 
-	database.Database = database.CreateDatabase("btp-tracker")
+		log.Println("Connection established")
+		defer func() {
+			if err := database.Client.Disconnect(context.TODO()); err != nil {
+				panic(err)
+			}
+		}()
 
+		database.Database = database.CreateDatabase("btp-tracker")
+
+		if err != nil {
+			panic(err)
+		}
+		log.Println("Database created!")
+		mongoBackend := database.NewMongoStore(database.Database)
+		if err := mongoBackend.CreatePortfolioIndexes(); err != nil {
+			log.Fatalf("Cannot create the indexes of the portfolios: %s", err)
+		}
+		s.portfolios = mongoBackend
+		s.alerts = mongoBackend
+	} else {
+		log.Println("Not connecting to MongoDB: portfolios and alerts are disabled")
+	}
+
+	backend, err := openStore(kind)
 	if err != nil {
-		panic(err)
+		log.Fatalf("Cannot open the %s store: %s", kind, err)
 	}
-	log.Println("Database created!")
+	log.Printf("Storing the quotes in %s\n", kind)
+	s.quotes = backend
+	s.scrapes = backend
 
-	database.Database.Collection("btp")
-	database.Insert_element("btp", TableRow{})
+	taxation, err = analytics.TaxationFromEnv()
+	if err != nil {
+		log.Printf("Invalid taxation, falling back to the default one: %s\n", err)
+	}
+	log.Printf("Tax rate %.2f%%, stamp duty %.2f%%\n", taxation.Rate*100, taxation.StampDuty*100)
 
-	database.Database.Collection("bot")
-	database.Insert_element("bot", TableRow{})
+	if webhook := alerts.WebhookFromEnv(); webhook != nil {
+		s.notifiers = append(s.notifiers, webhook)
+		log.Printf("Delivering alerts to %s\n", webhook.URL)
+	}
+	email, err := alerts.EmailFromEnv()
+	if err != nil {
+		log.Printf("Invalid SMTP configuration, alerts will not be emailed: %s\n", err)
+	} else if email != nil {
+		s.notifiers = append(s.notifiers, email)
+		log.Printf("Emailing alerts to %s through %s:%d\n", strings.Join(email.To, ", "), email.Host, email.Port)
+	}
 
 	http.HandleFunc("/getRTData", getRTData)
-	http.HandleFunc("/getBTPData", getBTPData)
+	http.HandleFunc("/getBTPData", s.getBTPData)
 	http.HandleFunc("/getRTBOTData", getRTBOTData)
-	http.HandleFunc("/getBOTData", getBOTData)
+	http.HandleFunc("/getBOTData", s.getBOTData)
+	http.HandleFunc(bondsRoute, s.bondsHandler)
+	http.HandleFunc("/api/v1/curve", s.getCurve)
+	http.HandleFunc("/api/v1/relative-value", s.getRelativeValue)
+	http.HandleFunc("/api/v1/ladder", s.getLadder)
+	http.HandleFunc(portfoliosRoute, requireMongo(s.portfolios != nil, s.portfoliosHandler))
+	http.HandleFunc(portfoliosRoute+"/", requireMongo(s.portfolios != nil, s.portfoliosHandler))
+	http.HandleFunc(alertsRoute, requireMongo(s.alerts != nil, s.alertsHandler))
+	http.HandleFunc(alertsRoute+"/", requireMongo(s.alerts != nil, s.alertsHandler))
 
 	// Start the HTTP server in a goroutine
 	go func() {
@@ -568,45 +738,8 @@ func main() {
 	// Create a new cron scheduler
 	c := cron.New()
 
-	// Schedule the job to run every 10 seconds
-	_, err = c.AddFunc("* * * * *", func() {
-		// Your cron job logic goes here
-		var rows []TableRow
-
-		rows = retrieveData()
-		for _, r := range rows {
-			log.Println(r)
-			err := database.Insert_element("btp", DbRow{
-				ISIN:          r.ISIN,
-				Description:   r.Description,
-				Last:          r.Last,
-				Cedola:        r.Cedola,
-				Expiration:    r.Expiration,
-				InsertionDate: time.Now(),
-			})
-			if err != nil {
-				fmt.Println("Error:", err)
-			}
-		}
-
-		var rows_bot []TableRow
-
-		rows_bot = retrieveBOTData()
-		for _, r := range rows_bot {
-			log.Println(r)
-			err := database.Insert_element("bot", DbRow{
-				ISIN:          r.ISIN,
-				Description:   r.Description,
-				Last:          r.Last,
-				Cedola:        r.Cedola,
-				Expiration:    r.Expiration,
-				InsertionDate: time.Now(),
-			})
-			if err != nil {
-				fmt.Println("Error:", err)
-			}
-		}
-	})
+	// Schedule the job to run every minute
+	_, err = c.AddFunc("* * * * *", s.scrapeAll)
 
 	if err != nil {
 		fmt.Println("Error scheduling cron job:", err)
@@ -616,8 +749,16 @@ func main() {
 	// Start the cron scheduler
 	c.Start()
 
-	// Keep the program running indefinitely
-	select {}
+	// Keep the program running until it is stopped, then let the running scrape
+	// finish before closing the quote store.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	log.Println("Shutting down")
+	<-c.Stop().Done()
+	log.Println("Waiting for the alert deliveries")
+	s.deliveries.Wait()
+	closeStore(backend)
 	// http.HandleFunc("/pdf", request_pdf)
 	// log.Printf("Starting the server on port %s\n", port)
 	// log.Fatal(http.ListenAndServe(port, nil))
@@ -0,0 +1,91 @@
+package portfolio
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"btpTracker/backend/analytics"
+)
+
+// Income is a payment expected from a position.
+type Income struct {
+	ISIN        string `json:"ISIN"`
+	Description string `json:"Description"`
+	analytics.Payment
+	// Withholding tax on the coupon and on the gain at redemption, and the
+	// amount left after it.
+	Tax       float64 `json:"Tax"`
+	NetAmount float64 `json:"NetAmount"`
+}
+
+// PositionIncome returns the payments expected after the date from the nominal
+// still open of the position. The gain at redemption is taxed on the clean
+// price of each purchase, without the fees.
+func PositionIncome(position Position, description string, open []OpenLot, bond analytics.Bond, date time.Time, tax analytics.Taxation) ([]Income, error) {
+	nominal, gain := 0.0, 0.0
+	for _, o := range open {
+		nominal += o.Nominal
+		gain += o.Nominal * max(analytics.Par-o.Lot.Price, 0) / analytics.Par
+	}
+	if nominal == 0 {
+		return nil, nil
+	}
+
+	payments, err := bond.Schedule(date, nominal)
+	if errors.Is(err, analytics.ErrMatured) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	incomes := make([]Income, len(payments))
+	for i, p := range payments {
+		taxed := p.Coupon
+		if p.Redemption > 0 {
+			taxed += gain
+		}
+		incomes[i] = Income{
+			ISIN:        position.ISIN,
+			Description: description,
+			Payment:     p,
+			Tax:         taxed * tax.Rate,
+			NetAmount:   p.Amount - taxed*tax.Rate,
+		}
+	}
+	return incomes, nil
+}
+
+// Month of the income calendar, with the totals of its payments.
+type Month struct {
+	// Month in the YYYY-MM format.
+	Month       string   `json:"Month"`
+	Coupons     float64  `json:"Coupons"`
+	Redemptions float64  `json:"Redemptions"`
+	Amount      float64  `json:"Amount"`
+	Tax         float64  `json:"Tax"`
+	NetAmount   float64  `json:"NetAmount"`
+	Payments    []Income `json:"Payments"`
+}
+
+// Calendar sorts the payments by date and groups them by the month they are
+// paid in.
+func Calendar(incomes []Income) []Month {
+	sort.SliceStable(incomes, func(i, j int) bool {
+		return incomes[i].Date.Before(incomes[j].Date)
+	})
+	months := []Month{}
+	for _, income := range incomes {
+		name := income.Date.Format("2006-01")
+		if len(months) == 0 || months[len(months)-1].Month != name {
+			months = append(months, Month{Month: name})
+		}
+		m := &months[len(months)-1]
+		m.Coupons += income.Coupon
+		m.Redemptions += income.Redemption
+		m.Amount += income.Amount
+		m.Tax += income.Tax
+		m.NetAmount += income.NetAmount
+		m.Payments = append(m.Payments, income)
+	}
+	return months
+}
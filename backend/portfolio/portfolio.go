@@ -0,0 +1,101 @@
+// Package portfolio models the bonds held by the team: portfolios contain a
+// position for each ISIN, and positions are made of the lots bought and sold.
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/instrument"
+)
+
+var (
+	ErrNoName    = errors.New("name is required")
+	ErrNoNominal = errors.New("nominal must be positive")
+	ErrNoPrice   = errors.New("price must be positive")
+	ErrNoDate    = errors.New("date is required")
+	ErrFees      = errors.New("fees cannot be negative")
+	ErrSide      = fmt.Errorf("side must be %q or %q", Buy, Sell)
+)
+
+// Portfolio as stored in the `portfolios` collection.
+type Portfolio struct {
+	ID          primitive.ObjectID `json:"ID" bson:"_id,omitempty"`
+	Name        string             `json:"Name" bson:"Name"`
+	Description string             `json:"Description" bson:"Description"`
+	CreatedAt   time.Time          `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// Validate checks the fields set by the user.
+func (p *Portfolio) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return ErrNoName
+	}
+	return nil
+}
+
+// Position is the holding of an ISIN in a portfolio, as stored in the
+// `positions` collection.
+type Position struct {
+	ID          primitive.ObjectID `json:"ID" bson:"_id,omitempty"`
+	PortfolioID primitive.ObjectID `json:"PortfolioID" bson:"PortfolioID"`
+	ISIN        string             `json:"ISIN" bson:"ISIN"`
+	CreatedAt   time.Time          `json:"CreatedAt" bson:"CreatedAt"`
+}
+
+// Validate checks the fields set by the user.
+func (p *Position) Validate() error {
+	p.ISIN = strings.ToUpper(strings.TrimSpace(p.ISIN))
+	return instrument.ValidateISIN(p.ISIN)
+}
+
+// Side of a lot.
+type Side string
+
+const (
+	Buy  Side = "buy"
+	Sell Side = "sell"
+)
+
+// Lot is a purchase or a sale of a position, as stored in the `lots`
+// collection.
+type Lot struct {
+	ID          primitive.ObjectID `json:"ID" bson:"_id,omitempty"`
+	PortfolioID primitive.ObjectID `json:"PortfolioID" bson:"PortfolioID"`
+	PositionID  primitive.ObjectID `json:"PositionID" bson:"PositionID"`
+	ISIN        string             `json:"ISIN" bson:"ISIN"`
+	// Buy when empty.
+	Side Side `json:"Side" bson:"Side"`
+	// Nominal amount traded, e.g. 10000.
+	Nominal float64 `json:"Nominal" bson:"Nominal"`
+	// Clean price per 100 of nominal.
+	Price float64   `json:"Price" bson:"Price"`
+	Date  time.Time `json:"Date" bson:"Date"`
+	// Commissions paid for the trade.
+	Fees float64 `json:"Fees" bson:"Fees"`
+}
+
+// Validate checks the fields set by the user.
+func (l *Lot) Validate() error {
+	if l.Side == "" {
+		l.Side = Buy
+	}
+	switch {
+	case l.Side != Buy && l.Side != Sell:
+		return ErrSide
+	case l.Nominal <= 0:
+		return ErrNoNominal
+	case l.Price <= 0:
+		return ErrNoPrice
+	case l.Date.IsZero():
+		return ErrNoDate
+	case l.Fees < 0:
+		return ErrFees
+	}
+	return nil
+}
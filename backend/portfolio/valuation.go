@@ -0,0 +1,177 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/analytics"
+)
+
+var ErrOversold = errors.New("sold more than the nominal held")
+
+// Cost of the lot, per unit of nominal, fees included.
+func (l Lot) unitCost() float64 {
+	return l.Price/analytics.Par + l.Fees/l.Nominal
+}
+
+// OpenLot is the part of a purchase that has not been sold yet.
+type OpenLot struct {
+	Lot     Lot
+	Nominal float64
+}
+
+// Cost of the nominal still open, fees included.
+func (o OpenLot) Cost() float64 {
+	return o.Nominal * o.Lot.unitCost()
+}
+
+// Realisation is the gain or loss of a sale, matched to the purchases it closed.
+type Realisation struct {
+	LotID   primitive.ObjectID `json:"LotID"`
+	Date    time.Time          `json:"Date"`
+	Nominal float64            `json:"Nominal"`
+	// Cost of the purchases closed and proceeds of the sale, fees included.
+	Cost     float64 `json:"Cost"`
+	Proceeds float64 `json:"Proceeds"`
+	PnL      float64 `json:"PnL"`
+	NetPnL   float64 `json:"NetPnL"`
+}
+
+// FIFO matches the sales of the lots, sorted by date, to the oldest purchases
+// still open. Returns the purchases left open and the realisations of the
+// sales, with the gains taxed at the rate of the taxation (losses are not
+// refunded).
+func FIFO(lots []Lot, tax analytics.Taxation) ([]OpenLot, []Realisation, error) {
+	var open []OpenLot
+	var realised []Realisation
+	for _, lot := range lots {
+		if lot.Side != Sell {
+			open = append(open, OpenLot{Lot: lot, Nominal: lot.Nominal})
+			continue
+		}
+
+		r := Realisation{
+			LotID:    lot.ID,
+			Date:     lot.Date,
+			Nominal:  lot.Nominal,
+			Proceeds: lot.Nominal*lot.Price/analytics.Par - lot.Fees,
+		}
+		left := lot.Nominal
+		for left > 0 {
+			if len(open) == 0 {
+				return nil, nil, fmt.Errorf("%w on %s", ErrOversold, lot.Date.Format(time.DateOnly))
+			}
+			closed := min(left, open[0].Nominal)
+			r.Cost += closed * open[0].Lot.unitCost()
+			open[0].Nominal -= closed
+			left -= closed
+			if open[0].Nominal == 0 {
+				open = open[1:]
+			}
+		}
+		r.PnL = r.Proceeds - r.Cost
+		r.NetPnL = r.PnL - max(r.PnL, 0)*tax.Rate
+		realised = append(realised, r)
+	}
+	return open, realised, nil
+}
+
+// Amounts of a valuation, in euros.
+type Amounts struct {
+	// Cost of the nominal held, fees included.
+	Cost        float64 `json:"Cost"`
+	MarketValue float64 `json:"MarketValue"`
+	// Coupon accrued up to the settlement date of the valuation.
+	AccruedInterest float64 `json:"AccruedInterest"`
+	UnrealisedPnL   float64 `json:"UnrealisedPnL"`
+	RealisedPnL     float64 `json:"RealisedPnL"`
+	// Figures net of the withholding tax, due on the accrued coupon and on the
+	// gains.
+	NetAccruedInterest float64 `json:"NetAccruedInterest"`
+	NetUnrealisedPnL   float64 `json:"NetUnrealisedPnL"`
+	NetRealisedPnL     float64 `json:"NetRealisedPnL"`
+}
+
+func (a *Amounts) add(b Amounts) {
+	a.Cost += b.Cost
+	a.MarketValue += b.MarketValue
+	a.AccruedInterest += b.AccruedInterest
+	a.UnrealisedPnL += b.UnrealisedPnL
+	a.RealisedPnL += b.RealisedPnL
+	a.NetAccruedInterest += b.NetAccruedInterest
+	a.NetUnrealisedPnL += b.NetUnrealisedPnL
+	a.NetRealisedPnL += b.NetRealisedPnL
+}
+
+// Valuation of a position at the last price of its ISIN.
+type Valuation struct {
+	PositionID primitive.ObjectID `json:"PositionID"`
+	ISIN       string             `json:"ISIN"`
+	// Nominal held.
+	Nominal   float64   `json:"Nominal"`
+	Price     float64   `json:"Price"`
+	PriceDate time.Time `json:"PriceDate"`
+	Amounts
+	Realised []Realisation `json:"Realised"`
+	// Why the position could not be valued, if it could not.
+	Error string `json:"Error,omitempty"`
+}
+
+// Value marks the lots of the position, sorted by date, to the clean price of
+// the bond on the date. Trades are settled at their clean price: the accrued
+// interest paid and received on them is not accounted for.
+func Value(position Position, lots []Lot, bond analytics.Bond, price float64, date time.Time, tax analytics.Taxation) (Valuation, error) {
+	v := Valuation{PositionID: position.ID, ISIN: position.ISIN, Price: price, Realised: []Realisation{}}
+	open, realised, err := FIFO(lots, tax)
+	if err != nil {
+		return v, err
+	}
+	for _, r := range realised {
+		v.RealisedPnL += r.PnL
+		v.NetRealisedPnL += r.NetPnL
+	}
+	v.Realised = append(v.Realised, realised...)
+
+	for _, o := range open {
+		v.Nominal += o.Nominal
+		v.Cost += o.Cost()
+	}
+	if v.Nominal == 0 {
+		return v, nil
+	}
+
+	accrued := 0.0
+	settlement, err := bond.Settle(price, date)
+	if err == nil {
+		accrued = settlement.AccruedInterest
+	} else if !errors.Is(err, analytics.ErrMatured) {
+		return v, err
+	}
+	v.MarketValue = v.Nominal * price / analytics.Par
+	v.AccruedInterest = v.Nominal * accrued / analytics.Par
+	v.UnrealisedPnL = v.MarketValue - v.Cost
+	v.NetAccruedInterest = v.AccruedInterest * (1 - tax.Rate)
+	v.NetUnrealisedPnL = v.UnrealisedPnL - max(v.UnrealisedPnL, 0)*tax.Rate
+	return v, nil
+}
+
+// PortfolioValuation is the valuation of all the positions of a portfolio,
+// with their totals.
+type PortfolioValuation struct {
+	PortfolioID primitive.ObjectID `json:"PortfolioID"`
+	Date        time.Time          `json:"Date"`
+	Positions   []Valuation        `json:"Positions"`
+	// Totals of the positions that could be valued.
+	Amounts
+}
+
+// Add the valuation of a position to the portfolio.
+func (p *PortfolioValuation) Add(v Valuation) {
+	p.Positions = append(p.Positions, v)
+	if v.Error == "" {
+		p.Amounts.add(v.Amounts)
+	}
+}
@@ -0,0 +1,115 @@
+package portfolio
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/analytics"
+)
+
+func lot(side Side, nominal float64, price float64, day int, fees float64) Lot {
+	return Lot{
+		ID:      primitive.NewObjectID(),
+		Side:    side,
+		Nominal: nominal,
+		Price:   price,
+		Date:    time.Date(2026, time.March, day, 0, 0, 0, 0, time.UTC),
+		Fees:    fees,
+	}
+}
+
+func near(got float64, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+func TestFIFOPartialClose(t *testing.T) {
+	buy := lot(Buy, 10000, 98, 2, 0)
+	sell := lot(Sell, 4000, 101, 9, 0)
+	open, realised, err := FIFO([]Lot{buy, sell}, analytics.Taxation{Rate: 0.125})
+	if err != nil {
+		t.Fatalf("FIFO: %s", err)
+	}
+
+	if len(open) != 1 || open[0].Lot.ID != buy.ID || open[0].Nominal != 6000 {
+		t.Fatalf("open %+v, want 6000 of the purchase", open)
+	}
+	if !near(open[0].Cost(), 5880) {
+		t.Errorf("open cost %g, want 5880", open[0].Cost())
+	}
+	if len(realised) != 1 {
+		t.Fatalf("%d realisations, want 1", len(realised))
+	}
+	r := realised[0]
+	if r.LotID != sell.ID || r.Nominal != 4000 || !near(r.Cost, 3920) || !near(r.Proceeds, 4040) {
+		t.Errorf("realisation %+v, want 4000 costing 3920 sold for 4040", r)
+	}
+	if !near(r.PnL, 120) || !near(r.NetPnL, 105) {
+		t.Errorf("P&L %g, net %g; want 120 and 105", r.PnL, r.NetPnL)
+	}
+}
+
+func TestFIFOCloseSpanningLotsWithFees(t *testing.T) {
+	lots := []Lot{
+		lot(Buy, 5000, 97, 2, 5),
+		lot(Buy, 5000, 99, 3, 5),
+		lot(Buy, 5000, 100, 4, 5),
+		// Closes the first purchase and 3000 of the second one.
+		lot(Sell, 8000, 97.5, 10, 8),
+	}
+	open, realised, err := FIFO(lots, analytics.Taxation{Rate: 0.125})
+	if err != nil {
+		t.Fatalf("FIFO: %s", err)
+	}
+
+	if len(open) != 2 || open[0].Lot.ID != lots[1].ID || open[0].Nominal != 2000 || open[1].Nominal != 5000 {
+		t.Fatalf("open %+v, want 2000 of the second purchase and the third one", open)
+	}
+	// 2000 of the second purchase: 2000 * 0.99 and 2/5 of its fees.
+	if !near(open[0].Cost(), 1982) {
+		t.Errorf("cost of the second purchase %g, want 1982", open[0].Cost())
+	}
+
+	r := realised[0]
+	// 4850 + 5 for the first purchase, 2970 + 3 for 3000 of the second one.
+	if wantCost := 4855.0 + 2973; !near(r.Cost, wantCost) {
+		t.Errorf("cost %g, want %g", r.Cost, wantCost)
+	}
+	if wantProceeds := 7800.0 - 8; !near(r.Proceeds, wantProceeds) {
+		t.Errorf("proceeds %g, want %g", r.Proceeds, wantProceeds)
+	}
+	// Losses are not refunded.
+	if !near(r.PnL, -36) || !near(r.NetPnL, -36) {
+		t.Errorf("P&L %g, net %g; want -36 and -36", r.PnL, r.NetPnL)
+	}
+}
+
+func TestFIFOOversold(t *testing.T) {
+	lots := []Lot{
+		lot(Buy, 5000, 98, 2, 0),
+		lot(Sell, 3000, 99, 5, 0),
+		lot(Sell, 3000, 99, 6, 0),
+	}
+	_, _, err := FIFO(lots, analytics.DefaultTaxation)
+	if !errors.Is(err, ErrOversold) {
+		t.Errorf("FIFO: %v, want ErrOversold", err)
+	}
+}
+
+// A purchase and a sale of the same day, in the order they were entered.
+func TestFIFOSameDay(t *testing.T) {
+	lots := []Lot{
+		lot(Buy, 5000, 98, 2, 0),
+		lot(Sell, 5000, 99, 2, 0),
+	}
+	open, realised, err := FIFO(lots, analytics.DefaultTaxation)
+	if err != nil {
+		t.Fatalf("FIFO: %s", err)
+	}
+	if len(open) != 0 || len(realised) != 1 {
+		t.Errorf("%d open lots and %d realisations, want 0 and 1", len(open), len(realised))
+	}
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/analytics"
+	"btpTracker/backend/portfolio"
+	"btpTracker/backend/store"
+)
+
+const portfoliosRoute = "/api/v1/portfolios"
+
+// Errors of the input sent by the user.
+var errBadRequest = errors.New("bad request")
+
+// Write the error with the status it maps to.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, "Not found", http.StatusNotFound)
+	case errors.Is(err, store.ErrDuplicatePosition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, errBadRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, fmt.Sprintf("Internal error: %s", err), http.StatusInternalServerError)
+	}
+}
+
+// Decode the JSON body of the request into `dest` and validate it.
+func readJSON(r *http.Request, dest interface{ Validate() error }) error {
+	if err := json.NewDecoder(r.Body).Decode(dest); err != nil {
+		return fmt.Errorf("%w: cannot decode body: %s", errBadRequest, err)
+	}
+	if err := dest.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", errBadRequest, err)
+	}
+	return nil
+}
+
+// Write the value as the JSON response to a request that created it.
+func writeCreated(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, value)
+}
+
+// Parse the ids in the path.
+func parseIDs(hexes []string) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, len(hexes))
+	for i, hex := range hexes {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid id %s", errBadRequest, hex)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// Routes the portfolio endpoints:
+//   - /api/v1/portfolios: GET, POST
+//   - /api/v1/portfolios/{id}: GET, PUT, DELETE
+//   - /api/v1/portfolios/{id}/positions: GET, POST
+//   - /api/v1/portfolios/{id}/positions/{positionId}: GET, DELETE
+//   - /api/v1/portfolios/{id}/positions/{positionId}/lots: GET, POST
+//   - /api/v1/portfolios/{id}/positions/{positionId}/lots/{lotId}: PUT, DELETE
+//   - /api/v1/portfolios/{id}/valuation: GET
+//   - /api/v1/portfolios/{id}/income: GET
+func (s *server) portfoliosHandler(w http.ResponseWriter, r *http.Request) {
+	// Enable CORS.
+	(w).Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, portfoliosRoute), "/")
+	var parts []string
+	if path != "" {
+		parts = strings.Split(path, "/")
+	}
+
+	// Even parts are ids, odd parts are the names of the collections.
+	var hexes []string
+	for i := 0; i < len(parts); i += 2 {
+		hexes = append(hexes, parts[i])
+	}
+	ids, err := parseIDs(hexes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch {
+	case len(parts) == 0:
+		s.handlePortfolios(w, r)
+	case len(parts) == 1:
+		s.handlePortfolio(w, r, ids[0])
+	case len(parts) == 2 && parts[1] == "valuation":
+		s.getValuation(w, r, ids[0])
+	case len(parts) == 2 && parts[1] == "income":
+		s.getIncome(w, r, ids[0])
+	case len(parts) == 2 && parts[1] == "positions":
+		s.handlePositions(w, r, ids[0])
+	case len(parts) == 3 && parts[1] == "positions":
+		s.handlePosition(w, r, ids[0], ids[1])
+	case len(parts) == 4 && parts[1] == "positions" && parts[3] == "lots":
+		s.handleLots(w, r, ids[0], ids[1])
+	case len(parts) == 5 && parts[1] == "positions" && parts[3] == "lots":
+		s.handleLot(w, r, ids[0], ids[1], ids[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *server) handlePortfolios(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		portfolios, err := s.portfolios.ListPortfolios()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, portfolios)
+	case "POST":
+		var p portfolio.Portfolio
+		if err := readJSON(r, &p); err != nil {
+			writeError(w, err)
+			return
+		}
+		if err := s.portfolios.CreatePortfolio(&p); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeCreated(w, p)
+	default:
+		http.Error(w, "Only GET and POST are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *server) handlePortfolio(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) {
+	switch r.Method {
+	case "GET":
+		p, err := s.portfolios.GetPortfolio(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, p)
+	case "PUT":
+		var p portfolio.Portfolio
+		if err := readJSON(r, &p); err != nil {
+			writeError(w, err)
+			return
+		}
+		p.ID = id
+		if err := s.portfolios.UpdatePortfolio(p); err != nil {
+			writeError(w, err)
+			return
+		}
+		updated, err := s.portfolios.GetPortfolio(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, updated)
+	case "DELETE":
+		if err := s.portfolios.DeletePortfolio(id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Only GET, PUT and DELETE are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *server) handlePositions(w http.ResponseWriter, r *http.Request, portfolioID primitive.ObjectID) {
+	if _, err := s.portfolios.GetPortfolio(portfolioID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		positions, err := s.portfolios.ListPositions(portfolioID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, positions)
+	case "POST":
+		var p portfolio.Position
+		if err := readJSON(r, &p); err != nil {
+			writeError(w, err)
+			return
+		}
+		// Only the bonds tracked by the scraper can be held.
+		if _, ok := s.findInstrument(w, p.ISIN); !ok {
+			return
+		}
+		p.PortfolioID = portfolioID
+		if err := s.portfolios.CreatePosition(&p); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeCreated(w, p)
+	default:
+		http.Error(w, "Only GET and POST are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *server) handlePosition(w http.ResponseWriter, r *http.Request, portfolioID primitive.ObjectID, id primitive.ObjectID) {
+	switch r.Method {
+	case "GET":
+		p, err := s.portfolios.GetPosition(portfolioID, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		lots, err := s.portfolios.ListLots(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, struct {
+			*portfolio.Position
+			Lots []portfolio.Lot `json:"Lots"`
+		}{p, lots})
+	case "DELETE":
+		if err := s.portfolios.DeletePosition(portfolioID, id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Only GET and DELETE are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *server) handleLots(w http.ResponseWriter, r *http.Request, portfolioID primitive.ObjectID, positionID primitive.ObjectID) {
+	position, err := s.portfolios.GetPosition(portfolioID, positionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		lots, err := s.portfolios.ListLots(positionID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, lots)
+	case "POST":
+		var l portfolio.Lot
+		if err := readJSON(r, &l); err != nil {
+			writeError(w, err)
+			return
+		}
+		l.PortfolioID = portfolioID
+		l.PositionID = positionID
+		l.ISIN = position.ISIN
+		if err := s.portfolios.CreateLot(&l); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeCreated(w, l)
+	default:
+		http.Error(w, "Only GET and POST are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *server) handleLot(w http.ResponseWriter, r *http.Request, portfolioID primitive.ObjectID, positionID primitive.ObjectID, id primitive.ObjectID) {
+	position, err := s.portfolios.GetPosition(portfolioID, positionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case "PUT":
+		var l portfolio.Lot
+		if err := readJSON(r, &l); err != nil {
+			writeError(w, err)
+			return
+		}
+		l.ID = id
+		l.PortfolioID = portfolioID
+		l.PositionID = positionID
+		l.ISIN = position.ISIN
+		if err := s.portfolios.UpdateLot(l); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, l)
+	case "DELETE":
+		if err := s.portfolios.DeleteLot(positionID, id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Only PUT and DELETE are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+// List the positions of the portfolio with their lots traded before `before`.
+func (s *server) listHoldings(id primitive.ObjectID, before time.Time) ([]portfolio.Position, map[primitive.ObjectID][]portfolio.Lot, error) {
+	positions, err := s.portfolios.ListPositions(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lots, err := s.portfolios.ListPortfolioLots(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	lotsByPosition := map[primitive.ObjectID][]portfolio.Lot{}
+	for _, l := range lots {
+		if !l.Date.Before(before) {
+			continue
+		}
+		lotsByPosition[l.PositionID] = append(lotsByPosition[l.PositionID], l)
+	}
+	return positions, lotsByPosition, nil
+}
+
+// Marks the portfolio to market with the last price scraped for each ISIN.
+// This route accepts the following query parameters:
+//   - 'date': value the portfolio at the close of the day (YYYY-MM-DD), with
+//     the last prices scraped that day. Defaults to now, with the latest prices.
+func (s *server) getValuation(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) {
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, err := s.portfolios.GetPortfolio(id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	date, before := time.Now(), time.Now()
+	parsed, err := dateParam(r, "date")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !parsed.IsZero() {
+		date, before = parsed, parsed.AddDate(0, 0, 1)
+	}
+
+	positions, lotsByPosition, err := s.listHoldings(id, before)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	valuation := portfolio.PortfolioValuation{PortfolioID: id, Date: date, Positions: []portfolio.Valuation{}}
+	for _, position := range positions {
+		v, err := s.valuePosition(position, lotsByPosition[position.ID], date, before)
+		if err != nil {
+			v.Error = err.Error()
+		}
+		valuation.Add(v)
+	}
+	writeJSON(w, valuation)
+}
+
+// Value the position at the last price of its ISIN before `before`.
+func (s *server) valuePosition(position portfolio.Position, lots []portfolio.Lot, date time.Time, before time.Time) (portfolio.Valuation, error) {
+	empty := portfolio.Valuation{PositionID: position.ID, ISIN: position.ISIN, Realised: []portfolio.Realisation{}}
+	inst, err := s.quotes.Instrument(position.ISIN)
+	if err != nil {
+		return empty, fmt.Errorf("cannot retrieve the instrument: %w", err)
+	}
+	latest, err := s.quotes.Latest(inst.Source, position.ISIN, before)
+	if err != nil {
+		return empty, fmt.Errorf("cannot retrieve the price: %w", err)
+	}
+	v, err := portfolio.Value(position, lots, analytics.NewBond(*inst), latest.Price, date, taxation)
+	v.PriceDate = latest.InsertionDate
+	return v, err
+}
+
+// Serves the calendar of the coupons and redemptions expected from the nominal
+// held in the portfolio, by month.
+// This route accepts the following query parameters:
+//   - 'format': 'json' (default) for the months with their payments, or 'csv'
+//     for a row per payment.
+func (s *server) getIncome(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) {
+	if r.Method != "GET" {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(w, fmt.Sprintf("Invalid format %s", format), http.StatusBadRequest)
+		return
+	}
+	if _, err := s.portfolios.GetPortfolio(id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	now := time.Now()
+	positions, lotsByPosition, err := s.listHoldings(id, now)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var incomes []portfolio.Income
+	for _, position := range positions {
+		open, _, err := portfolio.FIFO(lotsByPosition[position.ID], taxation)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid lots of %s: %s", position.ISIN, err), http.StatusUnprocessableEntity)
+			return
+		}
+		inst, err := s.quotes.Instrument(position.ISIN)
+		if errors.Is(err, store.ErrNotFound) {
+			// The portfolio exists: the store does not know the bond, e.g. a
+			// memory store after a restart.
+			http.Error(w, fmt.Sprintf("No instrument %s in the store", position.ISIN), http.StatusUnprocessableEntity)
+			return
+		} else if err != nil {
+			writeError(w, err)
+			return
+		}
+		income, err := portfolio.PositionIncome(position, inst.Description, open, analytics.NewBond(*inst), now, taxation)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Cannot compute the cash flows of %s: %s", position.ISIN, err), http.StatusUnprocessableEntity)
+			return
+		}
+		incomes = append(incomes, income...)
+	}
+	months := portfolio.Calendar(incomes)
+
+	if format != "csv" {
+		writeJSON(w, months)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=income-%s.csv", id.Hex()))
+	out := csv.NewWriter(w)
+	out.Write([]string{"Month", "Date", "ISIN", "Description", "Coupon", "Redemption", "Amount", "Tax", "NetAmount"})
+	amount := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, m := range months {
+		for _, p := range m.Payments {
+			out.Write([]string{
+				m.Month,
+				p.Date.Format(time.DateOnly),
+				p.ISIN,
+				p.Description,
+				amount(p.Coupon),
+				amount(p.Redemption),
+				amount(p.Amount),
+				amount(p.Tax),
+				amount(p.NetAmount),
+			})
+		}
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		log.Printf("Error while writing the income of %s: %s\n", id.Hex(), err)
+	}
+}
@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrMissingColumn = errors.New("missing column")
+
+// Header maps the labels of the columns of a table to their position. Labels
+// are normalized with NormalizeLabel.
+type Header map[string]int
+
+// NormalizeLabel lowercases the label and collapses its spaces, so that
+// "Codice  ISIN" and "codice isin" are the same column.
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// NewHeader builds the header of a table from the text of its `th` cells.
+func NewHeader(labels []string) Header {
+	header := Header{}
+	for idx, label := range labels {
+		label = NormalizeLabel(label)
+		if _, exists := header[label]; !exists && label != "" {
+			header[label] = idx
+		}
+	}
+	return header
+}
+
+// Has reports whether the header has a column labeled `label`.
+func (h Header) Has(label string) bool {
+	_, ok := h[NormalizeLabel(label)]
+	return ok
+}
+
+// Cell returns the cell of the column labeled `label`, or an empty string if
+// either the column or the cell is missing.
+func (h Header) Cell(cells []string, label string) string {
+	idx, ok := h[NormalizeLabel(label)]
+	if !ok || idx >= len(cells) {
+		return ""
+	}
+	return cells[idx]
+}
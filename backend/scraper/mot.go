@@ -0,0 +1,76 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Website of Borsa Italiana.
+const BorsaURL = "https://www.borsaitaliana.it"
+
+// Path of the lists of the MOT market. The first verb is the segment of the
+// list (btp, bot, ...), the second one the page.
+const motListPath = "/borsa/obbligazioni/mot/%s/lista.html?&page=%d#"
+
+func init() {
+	Register(&MOTSource{Segment: "btp"})
+	Register(&MOTSource{Segment: "bot"})
+}
+
+// MOTSource is a list of the MOT market of Borsa Italiana. All the lists share
+// the same layout: ISIN, description, last price, coupon and expiration.
+type MOTSource struct {
+	// Segment of the list in the URL, also used as name of the source.
+	Segment string
+	// Host serving the list. If empty, BorsaURL is used.
+	BaseURL string
+}
+
+// Page parameter of the links of the pager.
+var pageParam = regexp.MustCompile(`[?&]page=(\d+)`)
+
+func (s *MOTSource) Name() string {
+	return s.Segment
+}
+
+func (s *MOTSource) ListURL(page int) string {
+	base := s.BaseURL
+	if base == "" {
+		base = BorsaURL
+	}
+	return base + fmt.Sprintf(motListPath, s.Segment, page)
+}
+
+// Pages reads the pager of the list: the number of pages is the highest page
+// linked from the first one.
+func (s *MOTSource) Pages(first *colly.HTMLElement) int {
+	pages := 0
+	first.ForEach("a[href]", func(_ int, link *colly.HTMLElement) {
+		match := pageParam.FindStringSubmatch(link.Attr("href"))
+		if match == nil {
+			return
+		}
+		if page, err := strconv.Atoi(match[1]); err == nil && page > pages {
+			pages = page
+		}
+	})
+	return pages
+}
+
+func (s *MOTSource) Columns() []string {
+	return []string{"Isin", "Descrizione", "Ultimo", "Cedola", "Scadenza"}
+}
+
+func (s *MOTSource) ParseRow(header Header, cells []string) TableRow {
+	return TableRow{
+		ISIN:        strings.Trim(strings.Split(header.Cell(cells, "Isin"), "-")[0], " "),
+		Description: header.Cell(cells, "Descrizione"),
+		Last:        header.Cell(cells, "Ultimo"),
+		Cedola:      header.Cell(cells, "Cedola"),
+		Expiration:  header.Cell(cells, "Scadenza"),
+	}
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"btpTracker/backend/instrument"
+)
+
+// Layout of the dates published by Borsa Italiana.
+const dateLayout = "02/01/2006"
+
+// Placeholder used by Borsa Italiana when there is no value, e.g. when a bond
+// has not been traded yet.
+const noValue = "-"
+
+var ErrEmpty = errors.New("empty value")
+
+// Quote is a TableRow with its values converted to typed fields. The raw
+// strings of the row are kept for audit.
+type Quote struct {
+	TableRow `bson:",inline"`
+	// Last price, meaningful only if Traded is set.
+	Price  float64 `json:"Price" bson:"Price"`
+	Traded bool    `json:"Traded" bson:"Traded"`
+	// Annual coupon rate, in percentage.
+	Coupon   float64   `json:"Coupon" bson:"Coupon"`
+	Maturity time.Time `json:"Maturity" bson:"Maturity"`
+}
+
+// RowError reports a value of a row that could not be parsed.
+type RowError struct {
+	ISIN  string
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("%s: cannot parse %s %q: %s", e.ISIN, e.Field, e.Value, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ParseNumber parses a number in the Italian format, with dot as thousands
+// separator and comma as decimal separator (e.g. "1.234,56").
+func ParseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if s == "" {
+		return 0, ErrEmpty
+	}
+	s = strings.ReplaceAll(s, ".", "")
+	s = strings.ReplaceAll(s, ",", ".")
+	return strconv.ParseFloat(s, 64)
+}
+
+// ParseDate parses a date in the dd/mm/yyyy format.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, ErrEmpty
+	}
+	return time.Parse(dateLayout, s)
+}
+
+// ParseQuote converts the raw strings of the row. The returned quote holds all
+// the values that could be parsed, while the error joins a RowError for each
+// value that could not.
+func ParseQuote(row TableRow) (Quote, error) {
+	quote := Quote{TableRow: row}
+	var errs []error
+	fail := func(field, value string, err error) {
+		errs = append(errs, &RowError{ISIN: row.ISIN, Field: field, Value: value, Err: err})
+	}
+
+	if strings.TrimSpace(row.Last) != noValue {
+		price, err := ParseNumber(row.Last)
+		if err != nil {
+			fail("Last", row.Last, err)
+		} else {
+			quote.Price = price
+			quote.Traded = true
+		}
+	}
+
+	// Zero coupon bonds have no coupon at all.
+	if strings.TrimSpace(row.Cedola) != noValue {
+		coupon, err := ParseNumber(row.Cedola)
+		if err != nil {
+			fail("Cedola", row.Cedola, err)
+		} else {
+			quote.Coupon = coupon
+		}
+	}
+
+	maturity, err := ParseDate(row.Expiration)
+	if err != nil {
+		fail("Expiration", row.Expiration, err)
+	} else {
+		quote.Maturity = maturity
+	}
+
+	return quote, errors.Join(errs...)
+}
+
+// Instrument returns the static data of the quoted bond, found at `seen` in the
+// list `source`.
+func (q Quote) Instrument(source string, seen time.Time) instrument.Instrument {
+	return instrument.New(source, q.ISIN, q.Description, q.Coupon, q.Maturity, seen)
+}
@@ -0,0 +1,104 @@
+// Package replay records the list pages of Borsa Italiana to disk and serves
+// them back, so that the scraper can run without reaching the live website.
+//
+// Pages are stored as <dir>/<source>/page-<n>.html. The scraper/testdata
+// directory holds a trimmed sample of the BTP and BOT lists; it can be
+// refreshed from the live website with `go run . -record scraper/testdata`,
+// and the backend can run against it with `go run . -replay scraper/testdata`.
+package replay
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gocolly/colly/v2"
+
+	"btpTracker/backend/scraper"
+)
+
+// Name of the file holding the given page.
+func pageFile(page int) string {
+	return fmt.Sprintf("page-%d.html", page)
+}
+
+// Record downloads every page of the source into dir and returns the number
+// of pages written.
+func Record(src scraper.InstrumentSource, dir string) (int, error) {
+	out := filepath.Join(dir, src.Name())
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return 0, err
+	}
+
+	pages := 1
+	current := 1
+	var writeErr error
+	c := colly.NewCollector()
+	c.OnResponse(func(r *colly.Response) {
+		if err := os.WriteFile(filepath.Join(out, pageFile(current)), r.Body, 0o644); err != nil {
+			writeErr = err
+		}
+	})
+	c.OnHTML("html", func(page *colly.HTMLElement) {
+		if current == 1 {
+			pages = max(src.Pages(page), 1)
+		}
+	})
+
+	for ; current <= pages; current++ {
+		if err := c.Visit(src.ListURL(current)); err != nil {
+			return current - 1, err
+		}
+		if writeErr != nil {
+			return current - 1, writeErr
+		}
+	}
+	log.Printf("Recorded %d pages of %s in %s\n", pages, src.Name(), out)
+	return pages, nil
+}
+
+// Handler serves the pages recorded in dir. The source is the directory of the
+// requested list (e.g. /borsa/obbligazioni/mot/btp/lista.html) and the page is
+// read from the `page` query parameter.
+func Handler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		source := path.Base(path.Dir(r.URL.Path))
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			parsed, err := strconv.Atoi(p)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid page %s", p), http.StatusBadRequest)
+				return
+			}
+			page = parsed
+		}
+
+		file := filepath.Join(dir, source, pageFile(page))
+		if _, err := os.Stat(file); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, file)
+	})
+}
+
+// NewServer starts a local server replaying the pages recorded in dir. The
+// caller must close it.
+func NewServer(dir string) *httptest.Server {
+	return httptest.NewServer(Handler(dir))
+}
+
+// Use points every registered MOT source to baseURL, e.g. the URL of a replay
+// server.
+func Use(baseURL string) {
+	for _, src := range scraper.Sources() {
+		if mot, ok := src.(*scraper.MOTSource); ok {
+			mot.BaseURL = baseURL
+		}
+	}
+}
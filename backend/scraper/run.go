@@ -0,0 +1,51 @@
+package scraper
+
+import (
+	"errors"
+	"time"
+)
+
+// Run records a scrape of a source and how many of its rows were stored. The
+// rows stored by the run carry its ID.
+type Run struct {
+	ID     string `json:"ID" bson:"_id"`
+	Source string `json:"Source" bson:"Source"`
+	// The run starts with the scrape of the source and ends once its rows are
+	// stored.
+	Start time.Time `json:"Start" bson:"Start"`
+	End   time.Time `json:"End" bson:"End"`
+	// Time spent on the source, in seconds. The runs of a scrape are stored
+	// together, once the curve is fitted to all of them: the time spent on the
+	// other sources and on the curve in between is not counted.
+	Duration float64 `json:"Duration" bson:"Duration"`
+	// Pages visited, rows found, quotes that passed validation and rejected
+	// rows.
+	Pages    int `json:"Pages" bson:"Pages"`
+	Rows     int `json:"Rows" bson:"Rows"`
+	Quotes   int `json:"Quotes" bson:"Quotes"`
+	Rejected int `json:"Rejected" bson:"Rejected"`
+	// Quotes written to the quote store.
+	Stored int `json:"Stored" bson:"Stored"`
+	// Errors met while scraping the pages and storing the rows.
+	Errors int `json:"Errors" bson:"Errors"`
+}
+
+// AddError counts the errors joined in err, if any.
+func (r *Run) AddError(err error) {
+	if err == nil {
+		return
+	}
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		r.Errors += len(joined.Unwrap())
+		return
+	}
+	r.Errors++
+}
+
+// Finish sets the end of the run and its duration, without the time spent
+// elsewhere while the run was waiting.
+func (r *Run) Finish(end time.Time, elsewhere time.Duration) {
+	r.End = end
+	r.Duration = (end.Sub(r.Start) - elsewhere).Seconds()
+}
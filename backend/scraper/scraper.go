@@ -0,0 +1,241 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// TableRow represents the structure of each row in the table
+type TableRow struct {
+	ISIN        string `json:"ISIN" bson:"ISIN"`
+	Description string `json:"Description" bson:"Description"`
+	Last        string `json:"Last" bson:"Last"`
+	Cedola      string `json:"Cedola" bson:"Cedola"`
+	Expiration  string `json:"Expiration" bson:"Expiration"`
+	// Cells of the columns that are not mapped to a field, by label.
+	Extra map[string]string `json:"Extra,omitempty" bson:"Extra,omitempty"`
+}
+
+// InstrumentSource describes a list of instruments published on Borsa
+// Italiana, e.g. the MOT list of the BTPs.
+type InstrumentSource interface {
+	// Name of the source. It is also the collection the rows are stored in.
+	Name() string
+	// ListURL returns the URL of the given page (starting from 1) of the list.
+	ListURL(page int) string
+	// Pages returns how many pages the list has, given the first page. Zero
+	// means that the number of pages is unknown: pages are then visited until
+	// one of them yields no new ISINs.
+	Pages(first *colly.HTMLElement) int
+	// Columns returns the labels of the columns the list must have. The first
+	// one identifies the table holding the list among the tables of the page.
+	Columns() []string
+	// ParseRow converts the cleaned text of the cells of a row into a TableRow,
+	// looking up the cells by the label of their column.
+	ParseRow(header Header, cells []string) TableRow
+}
+
+var (
+	sources = map[string]InstrumentSource{}
+	// Registration order, so that the sources are always scraped in the same
+	// order.
+	order []string
+)
+
+// Register makes the source available to Sources and Lookup. Registering two
+// sources with the same name panics.
+func Register(src InstrumentSource) {
+	if _, exists := sources[src.Name()]; exists {
+		panic(fmt.Sprintf("scraper: source %s registered twice", src.Name()))
+	}
+	sources[src.Name()] = src
+	order = append(order, src.Name())
+}
+
+// Sources returns all the registered sources in registration order.
+func Sources() []InstrumentSource {
+	all := make([]InstrumentSource, len(order))
+	for i, name := range order {
+		all[i] = sources[name]
+	}
+	return all
+}
+
+// Lookup returns the source registered as `name`.
+func Lookup(name string) (InstrumentSource, bool) {
+	src, ok := sources[name]
+	return src, ok
+}
+
+// Remove new lines and surrounding spaces from the text of a cell.
+func cleanCell(text string) string {
+	return strings.TrimSpace(strings.ReplaceAll(text, "\n", ""))
+}
+
+// Upper bound on the pages visited, whether the pager reports them or not.
+const maxPages = 50
+
+// ErrTooManyPages is returned, together with the rows of the first maxPages
+// pages, when the pager reports more pages than maxPages.
+var ErrTooManyPages = errors.New("too many pages")
+
+// Result of the scraping of a source.
+type Result struct {
+	Source string
+	// Number of pages visited.
+	Pages int
+	Rows  []TableRow
+	// Rows that passed validation.
+	Quotes []Quote
+	// Rows that did not pass validation.
+	Rejected []Rejection
+}
+
+// Scrape visits every page of the source and returns all the rows found.
+//
+// A page that cannot be retrieved does not stop the scraping: the rows of the
+// other pages are still returned, together with the joined errors. A list
+// missing one of the columns of the source, instead, fails the whole run with
+// ErrMissingColumn and no result.
+func Scrape(src InstrumentSource) (*Result, error) {
+	log.Printf("Start retrieving %s\n", src.Name())
+	result := &Result{Source: src.Name()}
+	var errs []error
+	pages := 0
+	discovered := false
+
+	c := colly.NewCollector()
+	c.OnHTML("html", func(page *colly.HTMLElement) {
+		if !discovered {
+			pages = src.Pages(page)
+			discovered = true
+		}
+	})
+	columns := src.Columns()
+	var missing error
+	found := false
+	c.OnHTML("table", func(table *colly.HTMLElement) {
+		// The header is the first row with `th` cells.
+		var labels []string
+		table.ForEachWithBreak("tr", func(_ int, tr *colly.HTMLElement) bool {
+			tr.ForEach("th", func(_ int, th *colly.HTMLElement) {
+				labels = append(labels, cleanCell(th.Text))
+			})
+			return len(labels) == 0
+		})
+		header := NewHeader(labels)
+		if !header.Has(columns[0]) {
+			// Not the table of the list.
+			return
+		}
+		found = true
+		for _, label := range columns[1:] {
+			if !header.Has(label) {
+				missing = fmt.Errorf("%s: %w %q", table.Request.URL, ErrMissingColumn, label)
+				return
+			}
+		}
+
+		required := map[string]bool{}
+		for _, label := range columns {
+			required[NormalizeLabel(label)] = true
+		}
+		table.ForEach("tr", func(_ int, row *colly.HTMLElement) {
+			var cells []string
+			empty := true
+			row.ForEach("td", func(_ int, col *colly.HTMLElement) {
+				cells = append(cells, cleanCell(col.Text))
+				empty = empty && cells[len(cells)-1] == ""
+			})
+			if empty {
+				// Header rows and spacers: not even a rejected quote.
+				return
+			}
+			tableRow := src.ParseRow(header, cells)
+			for label, idx := range header {
+				if !required[label] && idx < len(cells) {
+					if tableRow.Extra == nil {
+						tableRow.Extra = map[string]string{}
+					}
+					tableRow.Extra[label] = cells[idx]
+				}
+			}
+			result.Rows = append(result.Rows, tableRow)
+		})
+	})
+
+	seen := map[string]bool{}
+	for i := 1; i <= maxPages; i++ {
+		if discovered && pages > 0 && i > pages {
+			break
+		}
+		url := src.ListURL(i)
+		from := len(result.Rows)
+		if err := c.Visit(url); err != nil {
+			if discovered && pages == 0 {
+				// Without a pager, a missing page is the end of the list.
+				log.Printf("Stop retrieving %s at %s: %s\n", src.Name(), url, err)
+				break
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			if !discovered {
+				// Without the first page there is nothing to paginate.
+				break
+			}
+			continue
+		}
+		if missing == nil && !found && i == 1 {
+			missing = fmt.Errorf("%s: %w %q", url, ErrMissingColumn, columns[0])
+		}
+		if missing != nil {
+			// The layout of the list changed: storing rows whose values may
+			// be in the wrong fields is worse than storing nothing.
+			log.Printf("Layout of %s changed: %s\n", src.Name(), missing)
+			return nil, missing
+		}
+
+		// Drop the rows of the ISINs already listed: a list that shifts while
+		// it is paged must not yield the same quote twice.
+		kept := result.Rows[:from]
+		fresh := 0
+		for _, row := range result.Rows[from:] {
+			if row.ISIN != "" {
+				if seen[row.ISIN] {
+					continue
+				}
+				seen[row.ISIN] = true
+				fresh++
+			}
+			kept = append(kept, row)
+		}
+		if pages == 0 && fresh == 0 {
+			// The page repeats the previous ones: the list is over.
+			result.Rows = result.Rows[:from]
+			break
+		}
+		result.Rows = kept
+		result.Pages++
+	}
+	if pages > maxPages {
+		// The bonds listed after the last page visited are missing.
+		errs = append(errs, fmt.Errorf("%s: %w: %d, visited %d", src.Name(), ErrTooManyPages, pages, maxPages))
+	}
+	log.Printf("Retrieved %d rows in %d pages from %s\n", len(result.Rows), result.Pages, src.Name())
+
+	for _, row := range result.Rows {
+		quote, err := Validate(row)
+		if err != nil {
+			result.Rejected = append(result.Rejected, Rejection{Row: row, Reason: err.Error()})
+			continue
+		}
+		result.Quotes = append(result.Quotes, quote)
+	}
+	if len(result.Rejected) > 0 {
+		log.Printf("%d rows of %s rejected\n", len(result.Rejected), src.Name())
+	}
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,226 @@
+package scraper_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/scraper/replay"
+)
+
+// Scrape the segment from the pages recorded in dir.
+func scrape(t *testing.T, dir string, segment string) (*scraper.Result, error) {
+	t.Helper()
+	srv := replay.NewServer(dir)
+	defer srv.Close()
+	return scraper.Scrape(&scraper.MOTSource{Segment: segment, BaseURL: srv.URL})
+}
+
+func quotesByISIN(result *scraper.Result) map[string]scraper.Quote {
+	quotes := map[string]scraper.Quote{}
+	for _, q := range result.Quotes {
+		quotes[q.ISIN] = q
+	}
+	return quotes
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestScrapeRecordedLists(t *testing.T) {
+	tests := []struct {
+		segment  string
+		pages    int
+		rows     int
+		rejected []string
+		quotes   map[string]scraper.Quote
+	}{
+		{
+			segment:  "btp",
+			pages:    2,
+			rows:     7,
+			rejected: []string{"IT0005553729"},
+			quotes: map[string]scraper.Quote{
+				"IT0005240830": {Price: 98.95, Coupon: 2.2, Maturity: date(2027, time.June, 1)},
+				"IT0005436693": {Price: 86.40, Coupon: 0.6, Maturity: date(2031, time.August, 1)},
+				"IT0004923998": {Price: 104.30, Coupon: 4.75, Maturity: date(2044, time.September, 1)},
+			},
+		},
+		{
+			// No pager: the list ends at the first missing page.
+			segment: "bot",
+			pages:   1,
+			rows:    3,
+			quotes: map[string]scraper.Quote{
+				"IT0005603342": {Price: 99.78, Coupon: 0, Maturity: date(2026, time.November, 13)},
+				"IT0005621187": {Price: 97.55, Coupon: 0, Maturity: date(2027, time.October, 14)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.segment, func(t *testing.T) {
+			result, err := scrape(t, "testdata", tt.segment)
+			if err != nil {
+				t.Fatalf("Scrape: %s", err)
+			}
+			if result.Pages != tt.pages {
+				t.Errorf("Pages = %d, want %d", result.Pages, tt.pages)
+			}
+			if len(result.Rows) != tt.rows {
+				t.Errorf("%d rows, want %d", len(result.Rows), tt.rows)
+			}
+			if len(result.Quotes)+len(result.Rejected) != len(result.Rows) {
+				t.Errorf("%d quotes and %d rejected rows out of %d rows", len(result.Quotes), len(result.Rejected), len(result.Rows))
+			}
+
+			var rejected []string
+			for _, r := range result.Rejected {
+				rejected = append(rejected, r.Row.ISIN)
+			}
+			if fmt.Sprint(rejected) != fmt.Sprint(tt.rejected) {
+				t.Errorf("rejected %v, want %v", rejected, tt.rejected)
+			}
+
+			quotes := quotesByISIN(result)
+			for isin, want := range tt.quotes {
+				got, ok := quotes[isin]
+				if !ok {
+					t.Errorf("%s: no quote", isin)
+					continue
+				}
+				if got.Price != want.Price || got.Coupon != want.Coupon || !got.Maturity.Equal(want.Maturity) {
+					t.Errorf("%s: price %g, coupon %g, maturity %s; want %g, %g, %s", isin,
+						got.Price, got.Coupon, got.Maturity.Format(time.DateOnly),
+						want.Price, want.Coupon, want.Maturity.Format(time.DateOnly))
+				}
+			}
+		})
+	}
+}
+
+// Cells of a row of a list, by column.
+type listRow map[string]string
+
+var (
+	btp2027 = listRow{"Isin": "IT0005240830", "Descrizione": "Btp-1gn27 2,2%", "Ultimo": "98,95", "Cedola": "2,20", "Scadenza": "01/06/2027"}
+	btp2029 = listRow{"Isin": "IT0005365165", "Descrizione": "Btp-1ag29 3%", "Ultimo": "100,12", "Cedola": "3,00", "Scadenza": "01/08/2029"}
+	btp2031 = listRow{"Isin": "IT0005436693", "Descrizione": "Btp-1ag31 0,6%", "Ultimo": "86,40", "Cedola": "0,60", "Scadenza": "01/08/2031"}
+)
+
+// Write the page of the list of the segment to dir, with the given columns and
+// no pager.
+func writePage(t *testing.T, dir string, segment string, page int, columns []string, rows ...listRow) {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("<html><body><table><thead><tr>")
+	for _, c := range columns {
+		fmt.Fprintf(&b, "<th>%s</th>", c)
+	}
+	b.WriteString("</tr></thead><tbody>")
+	for _, row := range rows {
+		b.WriteString("<tr>")
+		for _, c := range columns {
+			fmt.Fprintf(&b, "<td>%s</td>", row[c])
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</tbody></table></body></html>")
+
+	out := filepath.Join(dir, segment)
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(out, fmt.Sprintf("page-%d.html", page)), []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScrapeReorderedColumns(t *testing.T) {
+	dir := t.TempDir()
+	writePage(t, dir, "btp", 1, []string{"Scadenza", "Var %", "Cedola", "Isin", "Ultimo", "Descrizione"}, btp2027, btp2029)
+
+	result, err := scrape(t, dir, "btp")
+	if err != nil {
+		t.Fatalf("Scrape: %s", err)
+	}
+	if len(result.Quotes) != 2 || len(result.Rejected) != 0 {
+		t.Fatalf("%d quotes and %d rejected rows, want 2 and 0", len(result.Quotes), len(result.Rejected))
+	}
+	got := quotesByISIN(result)["IT0005365165"]
+	if got.Description != "Btp-1ag29 3%" || got.Price != 100.12 || got.Coupon != 3 || !got.Maturity.Equal(date(2029, time.August, 1)) {
+		t.Errorf("IT0005365165 parsed as %+v", got)
+	}
+	if _, ok := got.Extra["var %"]; !ok {
+		t.Errorf("Extra = %v, want the unmapped column", got.Extra)
+	}
+}
+
+func TestScrapeMissingColumn(t *testing.T) {
+	dir := t.TempDir()
+	writePage(t, dir, "btp", 1, []string{"Isin", "Descrizione", "Ultimo", "Scadenza"}, btp2027, btp2029)
+
+	result, err := scrape(t, dir, "btp")
+	if !errors.Is(err, scraper.ErrMissingColumn) {
+		t.Fatalf("error %v, want ErrMissingColumn", err)
+	}
+	if result != nil {
+		t.Errorf("result %+v, want none", result)
+	}
+}
+
+func TestScrapeWithoutPagerStopsAtRepeatedPage(t *testing.T) {
+	dir := t.TempDir()
+	columns := []string{"Isin", "Descrizione", "Ultimo", "Cedola", "Scadenza"}
+	writePage(t, dir, "btp", 1, columns, btp2027, btp2029)
+	// The list shifted while it was paged: btp2029 is listed again.
+	writePage(t, dir, "btp", 2, columns, btp2029, btp2031)
+	// The page repeats the previous one: the list is over.
+	writePage(t, dir, "btp", 3, columns, btp2031)
+	writePage(t, dir, "btp", 4, columns, btp2027)
+
+	result, err := scrape(t, dir, "btp")
+	if err != nil {
+		t.Fatalf("Scrape: %s", err)
+	}
+	if result.Pages != 2 {
+		t.Errorf("Pages = %d, want 2", result.Pages)
+	}
+	var isins []string
+	for _, q := range result.Quotes {
+		isins = append(isins, q.ISIN)
+	}
+	want := []string{"IT0005240830", "IT0005365165", "IT0005436693"}
+	if fmt.Sprint(isins) != fmt.Sprint(want) {
+		t.Errorf("quotes %v, want %v", isins, want)
+	}
+}
+
+func TestScrapeTooManyPages(t *testing.T) {
+	dir := t.TempDir()
+	columns := []string{"Isin", "Descrizione", "Ultimo", "Cedola", "Scadenza"}
+	writePage(t, dir, "btp", 1, columns, btp2027)
+	// The pager of the first page links the page 60.
+	first := filepath.Join(dir, "btp", "page-1.html")
+	html, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pager := `<a href="lista.html?&page=60">60</a></body>`
+	if err := os.WriteFile(first, []byte(strings.Replace(string(html), "</body>", pager, 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scrape(t, dir, "btp")
+	if !errors.Is(err, scraper.ErrTooManyPages) {
+		t.Errorf("error %v, want ErrTooManyPages", err)
+	}
+	if result == nil || len(result.Quotes) != 1 {
+		t.Fatalf("result %+v, want the quote of the first page", result)
+	}
+}
@@ -0,0 +1,32 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+
+	"btpTracker/backend/instrument"
+)
+
+var ErrNotTraded = errors.New("no price: not traded yet")
+
+// Rejection is a row that is not a valid quote, with the reason why.
+type Rejection struct {
+	Row    TableRow `json:"Row" bson:"Row"`
+	Reason string   `json:"Reason" bson:"Reason"`
+}
+
+// Validate parses the row and checks that it is a real quote: it must have a
+// valid ISIN, a price and parsable values.
+func Validate(row TableRow) (Quote, error) {
+	if err := instrument.ValidateISIN(row.ISIN); err != nil {
+		return Quote{}, fmt.Errorf("invalid ISIN %q: %w", row.ISIN, err)
+	}
+	quote, err := ParseQuote(row)
+	if err != nil {
+		return quote, err
+	}
+	if !quote.Traded {
+		return quote, ErrNotTraded
+	}
+	return quote, nil
+}
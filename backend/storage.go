@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"btpTracker/backend/database"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/store"
+	"btpTracker/backend/store/memory"
+	"btpTracker/backend/store/sqlite"
+)
+
+// Backends of the store, selected with the STORE environment variable.
+const (
+	mongoStore  = "mongo"
+	sqliteStore = "sqlite"
+	memoryStore = "memory"
+)
+
+// SQLite database file used when SQLITE_PATH is not set.
+const defaultSQLitePath = "btp-tracker.db"
+
+// Backend of the store selected with the STORE environment variable.
+// Defaults to MongoDB.
+func storeKind() string {
+	if kind := os.Getenv("STORE"); kind != "" {
+		return kind
+	}
+	return mongoStore
+}
+
+// Whether to connect to MongoDB: always with the mongo store, otherwise only if
+// USE_MONGO is true, to keep the portfolios and the alerts there. MONGODB_URI
+// alone does not connect, as the .env file sets it for the mongo store.
+func useMongo(kind string) bool {
+	if kind == mongoStore {
+		return true
+	}
+	use, _ := strconv.ParseBool(os.Getenv("USE_MONGO"))
+	return use
+}
+
+// Open the store of the quotes and of the rest of the scrapes on the given
+// backend. The MongoDB one needs the connection to be established.
+func openStore(kind string) (store.Store, error) {
+	switch kind {
+	case mongoStore:
+		var sources []string
+		for _, src := range scraper.Sources() {
+			sources = append(sources, src.Name())
+		}
+		return database.OpenMongoStore(database.Database, sources)
+	case sqliteStore:
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = defaultSQLitePath
+		}
+		return sqlite.Open(path)
+	case memoryStore:
+		// Without MEMORY_SNAPSHOT the scrapes are lost on shutdown.
+		if path := os.Getenv("MEMORY_SNAPSHOT"); path != "" {
+			return memory.Open(path)
+		}
+		return memory.New(), nil
+	}
+	return nil, fmt.Errorf("unknown store %q, expected %q, %q or %q", kind, mongoStore, sqliteStore, memoryStore)
+}
+
+// Close the store, if it needs to: the memory one saves its snapshot.
+func closeStore(backend store.Store) {
+	closer, ok := backend.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		log.Printf("Cannot close the store: %s\n", err)
+	}
+}
+
+// Wrap the handler of a feature that keeps its data in MongoDB, which is not
+// available when the tracker runs without it: then its store is missing.
+func requireMongo(available bool, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			// Enable CORS.
+			(w).Header().Set("Access-Control-Allow-Origin", "*")
+			http.Error(w, "This feature needs MongoDB, set USE_MONGO to enable it", http.StatusServiceUnavailable)
+			return
+		}
+		handler(w, r)
+	}
+}
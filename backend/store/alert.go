@@ -0,0 +1,30 @@
+package store
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/alerts"
+)
+
+// AlertStore keeps the alert rules and the log of the deliveries of their
+// notifications. Missing rules yield ErrNotFound.
+type AlertStore interface {
+	CreateAlertRule(r *alerts.Rule) error
+	ListAlertRules() ([]alerts.Rule, error)
+	GetAlertRule(id primitive.ObjectID) (*alerts.Rule, error)
+	// UpdateAlertRule replaces the condition of the rule, keeping the last time
+	// it triggered.
+	UpdateAlertRule(r alerts.Rule) error
+	// DeleteAlertRule deletes the rule. Its deliveries are kept.
+	DeleteAlertRule(id primitive.ObjectID) error
+	// SetAlertTriggered records that the rule triggered at the date, starting
+	// its cooldown.
+	SetAlertTriggered(id primitive.ObjectID, date time.Time) error
+
+	InsertAlertDelivery(d *alerts.Delivery) error
+	// ListAlertDeliveries returns the deliveries of the notifications of the
+	// rule, most recent first.
+	ListAlertDeliveries(ruleID primitive.ObjectID) ([]alerts.Delivery, error)
+}
@@ -0,0 +1,327 @@
+// Package memory keeps the quotes in memory, for tests and demos that run
+// without a database. The quotes can be saved to a JSON file on shutdown and
+// loaded back at startup.
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/instrument"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/store"
+)
+
+// Store keeps the rows of every source sorted by InsertionDate. It is safe for
+// concurrent use.
+type Store struct {
+	mu          sync.RWMutex
+	quotes      map[string][]store.Row
+	instruments map[string]instrument.Instrument
+	curves      []curve.Curve
+	rejected    []store.RejectedRow
+	runs        []scraper.Run
+	// JSON file the store is saved to on Close. Empty if it is not persisted.
+	path string
+}
+
+var _ store.Store = (*Store)(nil)
+
+// Content of the JSON file.
+type snapshot struct {
+	Quotes      map[string][]store.Row  `json:"Quotes"`
+	Instruments []instrument.Instrument `json:"Instruments"`
+	Curves      []curve.Curve           `json:"Curves"`
+	Rejected    []store.RejectedRow     `json:"Rejected"`
+	Runs        []scraper.Run           `json:"Runs"`
+}
+
+// New returns an empty store that is not persisted.
+func New() *Store {
+	return &Store{
+		quotes:      map[string][]store.Row{},
+		instruments: map[string]instrument.Instrument{},
+	}
+}
+
+// Open returns a store saved to the JSON file at the path on Close, loading
+// the quotes the file already holds.
+func Open(path string) (*Store, error) {
+	s := New()
+	s.path = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	for source, rows := range snap.Quotes {
+		if _, err := s.InsertSnapshot(source, rows); err != nil {
+			return nil, err
+		}
+	}
+	for _, inst := range snap.Instruments {
+		s.instruments[inst.ISIN] = inst
+	}
+	s.curves = snap.Curves
+	s.rejected = snap.Rejected
+	s.runs = snap.Runs
+	return s, nil
+}
+
+// Save writes the store to its JSON file. The file is replaced at once, so that
+// a crash while saving does not lose the previous snapshot.
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.RLock()
+	snap := snapshot{
+		Quotes:      s.quotes,
+		Instruments: s.allInstruments(),
+		Curves:      s.curves,
+		Rejected:    s.rejected,
+		Runs:        s.runs,
+	}
+	data, err := json.Marshal(snap)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Close saves the store if it is persisted.
+func (s *Store) Close() error {
+	return s.Save()
+}
+
+func (s *Store) InsertSnapshot(source string, rows []store.Row) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.quotes[source]
+	for _, r := range rows {
+		if r.SpreadToCurve != nil {
+			// Do not share the spread with the caller.
+			spread := *r.SpreadToCurve
+			r.SpreadToCurve = &spread
+		}
+		// Runs are stored in order: the row usually goes at the end.
+		i := sort.Search(len(stored), func(i int) bool { return stored[i].InsertionDate.After(r.InsertionDate) })
+		stored = append(stored, store.Row{})
+		copy(stored[i+1:], stored[i:])
+		stored[i] = r
+	}
+	s.quotes[source] = stored
+	return len(rows), nil
+}
+
+// Index of the first row of the source stored at or after t.
+func (s *Store) search(source string, t time.Time) int {
+	rows := s.quotes[source]
+	return sort.Search(len(rows), func(i int) bool { return !rows[i].InsertionDate.Before(t) })
+}
+
+func (s *Store) History(source string, isin string, from time.Time, to time.Time) ([]store.Row, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows := s.quotes[source]
+	start, end := 0, len(rows)
+	if !from.IsZero() {
+		start = s.search(source, from)
+	}
+	if !to.IsZero() {
+		end = s.search(source, to)
+	}
+
+	results := []store.Row{}
+	for i := start; i < end; i++ {
+		if rows[i].ISIN == isin {
+			results = append(results, rows[i])
+		}
+	}
+	return results, nil
+}
+
+func (s *Store) Latest(source string, isin string, before time.Time) (*store.Row, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows := s.quotes[source]
+	for i := s.search(source, before) - 1; i >= 0; i-- {
+		if rows[i].ISIN == isin {
+			row := rows[i]
+			return &row, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *Store) LatestSnapshot(source string) ([]store.Row, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows := s.quotes[source]
+	if len(rows) == 0 {
+		return nil, store.ErrNotFound
+	}
+	last := rows[len(rows)-1].InsertionDate
+	start := s.search(source, last)
+	return append([]store.Row{}, rows[start:]...), nil
+}
+
+func (s *Store) SpreadStats(source string, since time.Time) ([]curve.SpreadStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows := s.quotes[source]
+
+	// Sums of the spreads and of their squares, by ISIN, in the order the
+	// ISINs are first seen.
+	type sums struct {
+		stats      curve.SpreadStats
+		sum, sumSq float64
+	}
+	byISIN := map[string]*sums{}
+	var order []string
+	for _, r := range rows[s.search(source, since):] {
+		if r.SpreadToCurve == nil {
+			continue
+		}
+		acc, ok := byISIN[r.ISIN]
+		if !ok {
+			acc = &sums{stats: curve.SpreadStats{ISIN: r.ISIN}}
+			byISIN[r.ISIN] = acc
+			order = append(order, r.ISIN)
+		}
+		spread := *r.SpreadToCurve
+		acc.stats.Spread = spread
+		acc.stats.Date = r.InsertionDate
+		acc.stats.Observations++
+		acc.sum += spread
+		acc.sumSq += spread * spread
+	}
+
+	var results []curve.SpreadStats
+	for _, isin := range order {
+		acc := byISIN[isin]
+		n := float64(acc.stats.Observations)
+		acc.stats.Mean = acc.sum / n
+		// Rounding can make the variance of equal spreads slightly negative.
+		acc.stats.StdDev = math.Sqrt(math.Max(acc.sumSq/n-acc.stats.Mean*acc.stats.Mean, 0))
+		results = append(results, acc.stats)
+	}
+	return results, nil
+}
+
+func (s *Store) UpsertInstruments(insts []instrument.Instrument) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, inst := range insts {
+		if known, ok := s.instruments[inst.ISIN]; ok {
+			inst.FirstSeen = known.FirstSeen
+		}
+		s.instruments[inst.ISIN] = inst
+	}
+	return nil
+}
+
+func (s *Store) Instrument(isin string) (*instrument.Instrument, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	inst, ok := s.instruments[isin]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &inst, nil
+}
+
+func (s *Store) Instruments(isins []string) ([]instrument.Instrument, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	results := []instrument.Instrument{}
+	seen := map[string]bool{}
+	for _, isin := range isins {
+		if inst, ok := s.instruments[isin]; ok && !seen[isin] {
+			seen[isin] = true
+			results = append(results, inst)
+		}
+	}
+	sortInstruments(results)
+	return results, nil
+}
+
+// All the instruments, sorted by ISIN. The caller holds the lock.
+func (s *Store) allInstruments() []instrument.Instrument {
+	results := make([]instrument.Instrument, 0, len(s.instruments))
+	for _, inst := range s.instruments {
+		results = append(results, inst)
+	}
+	sortInstruments(results)
+	return results
+}
+
+func sortInstruments(instruments []instrument.Instrument) {
+	sort.Slice(instruments, func(i, j int) bool { return instruments[i].ISIN < instruments[j].ISIN })
+}
+
+func (s *Store) InsertCurve(c curve.Curve) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.curves = append(s.curves, c)
+	return nil
+}
+
+func (s *Store) LatestCurve(before time.Time) (*curve.Curve, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var latest *curve.Curve
+	for i, c := range s.curves {
+		if c.Date.Before(before) && (latest == nil || c.Date.After(latest.Date)) {
+			latest = &s.curves[i]
+		}
+	}
+	if latest == nil {
+		return nil, store.ErrNotFound
+	}
+	c := *latest
+	return &c, nil
+}
+
+func (s *Store) InsertRejected(rows []store.RejectedRow) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejected = append(s.rejected, rows...)
+	return len(rows), nil
+}
+
+func (s *Store) InsertRun(run scraper.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = append(s.runs, run)
+	return nil
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/instrument"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/store"
+	"btpTracker/backend/store/storetest"
+)
+
+var start = time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+
+// Time of the n-th run, one a minute.
+func run(n int) time.Time {
+	return start.Add(time.Duration(n) * time.Minute)
+}
+
+func row(isin string, price float64, n int, spread *float64) store.Row {
+	return store.Row{ISIN: isin, Price: price, SpreadToCurve: spread, InsertionDate: run(n)}
+}
+
+func spread(bp float64) *float64 {
+	return &bp
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return New()
+	})
+}
+
+func TestSaveAndOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open of a missing file: %s", err)
+	}
+	for _, rows := range [][]store.Row{
+		{row("A", 100, 0, spread(10))},
+		{row("A", 101, 1, nil)},
+	} {
+		if _, err := s.InsertSnapshot("btp", rows); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.UpsertInstruments([]instrument.Instrument{{ISIN: "A", Maturity: run(0).AddDate(5, 0, 0)}})
+	s.InsertCurve(curve.Curve{Date: run(1), Bonds: 8})
+	s.InsertRun(scraper.Run{ID: "run", Source: "btp", Start: run(1), Pages: 2, Stored: 1})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	rows, err := reopened.History("btp", "A", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].SpreadToCurve == nil || *rows[0].SpreadToCurve != 10 || rows[1].SpreadToCurve != nil ||
+		!rows[1].InsertionDate.Equal(run(1)) {
+		t.Errorf("History after reopening = %+v", rows)
+	}
+	if inst, err := reopened.Instrument("A"); err != nil || !inst.Maturity.Equal(run(0).AddDate(5, 0, 0)) {
+		t.Errorf("Instrument after reopening = %+v, %v", inst, err)
+	}
+	if c, err := reopened.LatestCurve(run(2)); err != nil || c.Bonds != 8 {
+		t.Errorf("LatestCurve after reopening = %+v, %v", c, err)
+	}
+	if len(reopened.runs) != 1 || reopened.runs[0].ID != "run" || reopened.runs[0].Pages != 2 {
+		t.Errorf("runs after reopening = %+v", reopened.runs)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	s := New()
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(2)
+		go func(n int) {
+			defer wg.Done()
+			s.InsertSnapshot("btp", []store.Row{row("A", float64(n), n, nil)})
+		}(n)
+		go func() {
+			defer wg.Done()
+			s.History("btp", "A", time.Time{}, time.Time{})
+			s.LatestSnapshot("btp")
+		}()
+	}
+	wg.Wait()
+
+	rows, _ := s.History("btp", "A", time.Time{}, time.Time{})
+	if len(rows) != 20 {
+		t.Fatalf("%d rows, want 20", len(rows))
+	}
+	for i, r := range rows {
+		if r.Price != float64(i) {
+			t.Errorf("row %d has price %g: the rows are not sorted by run", i, r.Price)
+		}
+	}
+}
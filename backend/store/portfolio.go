@@ -0,0 +1,42 @@
+package store
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"btpTracker/backend/portfolio"
+)
+
+var ErrDuplicatePosition = errors.New("the portfolio already has a position in this ISIN")
+
+// PortfolioStore keeps the portfolios, their positions and the lots of the
+// positions. Missing documents yield ErrNotFound.
+type PortfolioStore interface {
+	CreatePortfolio(p *portfolio.Portfolio) error
+	ListPortfolios() ([]portfolio.Portfolio, error)
+	GetPortfolio(id primitive.ObjectID) (*portfolio.Portfolio, error)
+	// UpdatePortfolio replaces the name and the description of the portfolio.
+	UpdatePortfolio(p portfolio.Portfolio) error
+	// DeletePortfolio deletes the portfolio with all its positions and lots.
+	DeletePortfolio(id primitive.ObjectID) error
+
+	// CreatePosition opens the position, or returns ErrDuplicatePosition if the
+	// portfolio already has one in the same ISIN.
+	CreatePosition(p *portfolio.Position) error
+	ListPositions(portfolioID primitive.ObjectID) ([]portfolio.Position, error)
+	GetPosition(portfolioID primitive.ObjectID, id primitive.ObjectID) (*portfolio.Position, error)
+	// DeletePosition deletes the position with all its lots.
+	DeletePosition(portfolioID primitive.ObjectID, id primitive.ObjectID) error
+
+	CreateLot(l *portfolio.Lot) error
+	// ListLots returns the lots of the position, oldest first. Lots of the
+	// same day come in the order they were entered.
+	ListLots(positionID primitive.ObjectID) ([]portfolio.Lot, error)
+	// ListPortfolioLots returns the lots of all the positions of the
+	// portfolio, in the order of ListLots.
+	ListPortfolioLots(portfolioID primitive.ObjectID) ([]portfolio.Lot, error)
+	// UpdateLot replaces the trade data of the lot.
+	UpdateLot(l portfolio.Lot) error
+	DeleteLot(positionID primitive.ObjectID, id primitive.ObjectID) error
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"time"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/scraper"
+)
+
+// RejectedRow is a row that did not pass validation, as stored by a scrape run.
+type RejectedRow struct {
+	Source            string `json:"Source" bson:"Source"`
+	scraper.Rejection `bson:",inline"`
+	InsertionDate     time.Time `json:"InsertionDate" bson:"InsertionDate"`
+	// ID of the scrape run that rejected the row.
+	RunID string `json:"RunID" bson:"RunID"`
+}
+
+// ScrapeStore keeps what a scrape yields besides the quotes: the curve fitted
+// to the snapshot, the rows rejected by the validation and the log of the runs.
+type ScrapeStore interface {
+	InsertCurve(c curve.Curve) error
+	// LatestCurve returns the most recent curve fitted before `before`, or
+	// ErrNotFound.
+	LatestCurve(before time.Time) (*curve.Curve, error)
+	// InsertRejected stores the rows rejected by a run. It returns how many
+	// rows were stored, also when some of them could not be.
+	InsertRejected(rows []RejectedRow) (int, error)
+	InsertRun(run scraper.Run) error
+}
+
+// Store keeps both the quotes and the rest of the scrapes, as every backend
+// does.
+type Store interface {
+	QuoteStore
+	ScrapeStore
+}
@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Migrations of the schema, applied in order. Each one runs once, in a
+// transaction, and is recorded in `schema_migrations` by its position in the
+// list (starting from 1): append new migrations and never change the applied
+// ones.
+var migrations = []string{
+	// 1: quotes and instruments. Times are stored in Unix milliseconds, UTC.
+	`CREATE TABLE quotes (
+		source            TEXT    NOT NULL,
+		isin              TEXT    NOT NULL,
+		price             REAL    NOT NULL,
+		ytm               REAL    NOT NULL DEFAULT 0,
+		net_ytm           REAL    NOT NULL DEFAULT 0,
+		days_to_maturity  INTEGER NOT NULL DEFAULT 0,
+		simple_yield      REAL    NOT NULL DEFAULT 0,
+		macaulay_duration REAL    NOT NULL DEFAULT 0,
+		modified_duration REAL    NOT NULL DEFAULT 0,
+		dv01              REAL    NOT NULL DEFAULT 0,
+		convexity         REAL    NOT NULL DEFAULT 0,
+		spread_to_curve   REAL,
+		insertion_date    INTEGER NOT NULL
+	);
+	CREATE INDEX quotes_isin ON quotes (source, isin, insertion_date);
+	CREATE INDEX quotes_date ON quotes (source, insertion_date);
+	CREATE TABLE instruments (
+		isin             TEXT    PRIMARY KEY,
+		description      TEXT    NOT NULL,
+		coupon           REAL    NOT NULL,
+		coupon_frequency INTEGER NOT NULL,
+		maturity         INTEGER NOT NULL,
+		issue_type       TEXT    NOT NULL,
+		source           TEXT    NOT NULL,
+		first_seen       INTEGER NOT NULL,
+		last_seen        INTEGER NOT NULL
+	);`,
+	// 2: ID of the scrape run that stored the quote.
+	`ALTER TABLE quotes ADD COLUMN run_id TEXT;`,
+	// 3: curves, rejected rows and scrape runs. Curves and rejected rows are
+	// kept as JSON, as they are only read back whole.
+	`CREATE TABLE curves (
+		date  INTEGER NOT NULL,
+		curve TEXT    NOT NULL
+	);
+	CREATE INDEX curves_date ON curves (date);
+	CREATE TABLE rejected_rows (
+		source         TEXT    NOT NULL,
+		run_id         TEXT    NOT NULL,
+		insertion_date INTEGER NOT NULL,
+		rejection      TEXT    NOT NULL
+	);
+	CREATE TABLE scrape_runs (
+		id       TEXT    PRIMARY KEY,
+		source   TEXT    NOT NULL,
+		start    INTEGER NOT NULL,
+		end      INTEGER NOT NULL,
+		duration REAL    NOT NULL,
+		rows     INTEGER NOT NULL,
+		quotes   INTEGER NOT NULL,
+		rejected INTEGER NOT NULL,
+		stored   INTEGER NOT NULL,
+		errors   INTEGER NOT NULL
+	);`,
+	// 4: pages visited by the scrape runs. The runs logged before are left at 0.
+	`ALTER TABLE scrape_runs ADD COLUMN pages INTEGER NOT NULL DEFAULT 0;`,
+}
+
+// Apply the migrations the database has not seen yet.
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UnixMilli()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		log.Printf("Applied migration %d of the SQLite schema\n", version)
+	}
+	return nil
+}
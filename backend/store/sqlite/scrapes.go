@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/store"
+)
+
+func (s *Store) InsertCurve(c curve.Curve) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO curves (date, curve) VALUES (?, ?)`, toMillis(c.Date), string(data))
+	return err
+}
+
+func (s *Store) LatestCurve(before time.Time) (*curve.Curve, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT curve FROM curves WHERE date < ? ORDER BY date DESC LIMIT 1`, toMillis(before)).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	c := &curve.Curve{}
+	if err := json.Unmarshal([]byte(data), c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// The rows are inserted in a transaction: either all of them are stored or none.
+func (s *Store) InsertRejected(rows []store.RejectedRow) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO rejected_rows (source, run_id, insertion_date, rejection) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		rejection, err := json.Marshal(r.Rejection)
+		if err == nil {
+			_, err = stmt.Exec(r.Source, r.RunID, toMillis(r.InsertionDate), string(rejection))
+		}
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func (s *Store) InsertRun(run scraper.Run) error {
+	_, err := s.db.Exec(`INSERT INTO scrape_runs
+		(id, source, start, end, duration, pages, rows, quotes, rejected, stored, errors)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.Source, toMillis(run.Start), toMillis(run.End), run.Duration,
+		run.Pages, run.Rows, run.Quotes, run.Rejected, run.Stored, run.Errors)
+	return err
+}
@@ -0,0 +1,294 @@
+// Package sqlite keeps the quotes in an SQLite database file, so that the
+// tracker can run without a database server. The driver is pure Go and needs
+// no cgo.
+package sqlite
+
+import (
+	"database/sql"
+	"math"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/instrument"
+	"btpTracker/backend/store"
+)
+
+// Store keeps the quotes of all the sources in the `quotes` table, the
+// instruments in the `instruments` table and the rest of the scrapes in the
+// `curves`, `rejected_rows` and `scrape_runs` tables.
+type Store struct {
+	db *sql.DB
+}
+
+var _ store.Store = (*Store)(nil)
+
+// Open opens the database file at the path, creating it if needed, and
+// migrates its schema.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer: serialize the connections instead of
+	// failing with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Times are stored in Unix milliseconds, as in MongoDB.
+func toMillis(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.UnixMilli(ms).UTC()
+}
+
+const quoteColumns = `isin, price, ytm, net_ytm, days_to_maturity, simple_yield,
+	macaulay_duration, modified_duration, dv01, convexity, spread_to_curve, insertion_date, run_id`
+
+// Scan the quotes selected with quoteColumns.
+func scanRows(rows *sql.Rows) ([]store.Row, error) {
+	defer rows.Close()
+	results := []store.Row{}
+	for rows.Next() {
+		var r store.Row
+		var spread sql.NullFloat64
+		var date int64
+		var runID sql.NullString
+		err := rows.Scan(&r.ISIN, &r.Price, &r.YTM, &r.NetYTM, &r.DaysToMaturity, &r.SimpleYield,
+			&r.MacaulayDuration, &r.ModifiedDuration, &r.DV01, &r.Convexity, &spread, &date, &runID)
+		if err != nil {
+			return nil, err
+		}
+		if spread.Valid {
+			r.SpreadToCurve = &spread.Float64
+		}
+		r.InsertionDate = fromMillis(date)
+		r.RunID = runID.String
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// The rows are inserted in a transaction: either all of them are stored or none.
+func (s *Store) InsertSnapshot(source string, rows []store.Row) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO quotes (source, ` + quoteColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, r := range rows {
+		var spread sql.NullFloat64
+		if r.SpreadToCurve != nil {
+			spread = sql.NullFloat64{Float64: *r.SpreadToCurve, Valid: true}
+		}
+		runID := sql.NullString{String: r.RunID, Valid: r.RunID != ""}
+		m := r.Metrics
+		_, err := stmt.Exec(source, r.ISIN, r.Price, m.YTM, m.NetYTM, m.DaysToMaturity, m.SimpleYield,
+			m.MacaulayDuration, m.ModifiedDuration, m.DV01, m.Convexity, spread, toMillis(r.InsertionDate), runID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func (s *Store) History(source string, isin string, from time.Time, to time.Time) ([]store.Row, error) {
+	query := `SELECT ` + quoteColumns + ` FROM quotes WHERE source = ? AND isin = ?`
+	args := []any{source, isin}
+	if !from.IsZero() {
+		query += ` AND insertion_date >= ?`
+		args = append(args, toMillis(from))
+	}
+	if !to.IsZero() {
+		query += ` AND insertion_date < ?`
+		args = append(args, toMillis(to))
+	}
+	rows, err := s.db.Query(query+` ORDER BY insertion_date`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows)
+}
+
+func (s *Store) Latest(source string, isin string, before time.Time) (*store.Row, error) {
+	rows, err := s.db.Query(`SELECT `+quoteColumns+` FROM quotes
+		WHERE source = ? AND isin = ? AND insertion_date < ?
+		ORDER BY insertion_date DESC LIMIT 1`, source, isin, toMillis(before))
+	if err != nil {
+		return nil, err
+	}
+	results, err := scanRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return &results[0], nil
+}
+
+func (s *Store) LatestSnapshot(source string) ([]store.Row, error) {
+	rows, err := s.db.Query(`SELECT `+quoteColumns+` FROM quotes
+		WHERE source = ? AND insertion_date = (SELECT MAX(insertion_date) FROM quotes WHERE source = ?)`,
+		source, source)
+	if err != nil {
+		return nil, err
+	}
+	results, err := scanRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return results, nil
+}
+
+func (s *Store) SpreadStats(source string, since time.Time) ([]curve.SpreadStats, error) {
+	rows, err := s.db.Query(`SELECT w.isin, q.spread_to_curve, w.last, w.mean, w.variance, w.observations
+		FROM (
+			SELECT isin,
+				MAX(insertion_date) AS last,
+				AVG(spread_to_curve) AS mean,
+				AVG(spread_to_curve * spread_to_curve) - AVG(spread_to_curve) * AVG(spread_to_curve) AS variance,
+				COUNT(*) AS observations
+			FROM quotes
+			WHERE source = ? AND spread_to_curve IS NOT NULL AND insertion_date >= ?
+			GROUP BY isin
+		) w
+		JOIN quotes q ON q.source = ? AND q.isin = w.isin AND q.insertion_date = w.last
+		WHERE q.spread_to_curve IS NOT NULL`, source, toMillis(since), source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []curve.SpreadStats
+	for rows.Next() {
+		var st curve.SpreadStats
+		var last int64
+		var variance float64
+		if err := rows.Scan(&st.ISIN, &st.Spread, &last, &st.Mean, &variance, &st.Observations); err != nil {
+			return nil, err
+		}
+		st.Date = fromMillis(last)
+		// Rounding can make the variance of equal spreads slightly negative.
+		st.StdDev = math.Sqrt(math.Max(variance, 0))
+		results = append(results, st)
+	}
+	return results, rows.Err()
+}
+
+// The instruments are upserted in a transaction.
+func (s *Store) UpsertInstruments(insts []instrument.Instrument) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO instruments
+		(isin, description, coupon, coupon_frequency, maturity, issue_type, source, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (isin) DO UPDATE SET
+			description = excluded.description,
+			coupon = excluded.coupon,
+			coupon_frequency = excluded.coupon_frequency,
+			maturity = excluded.maturity,
+			issue_type = excluded.issue_type,
+			source = excluded.source,
+			last_seen = excluded.last_seen`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, inst := range insts {
+		_, err := stmt.Exec(inst.ISIN, inst.Description, inst.Coupon, inst.CouponFrequency, toMillis(inst.Maturity),
+			string(inst.IssueType), inst.Source, toMillis(inst.FirstSeen), toMillis(inst.LastSeen))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const instrumentColumns = `isin, description, coupon, coupon_frequency, maturity, issue_type, source, first_seen, last_seen`
+
+// Scan the instruments selected with instrumentColumns.
+func scanInstruments(rows *sql.Rows) ([]instrument.Instrument, error) {
+	defer rows.Close()
+	results := []instrument.Instrument{}
+	for rows.Next() {
+		var inst instrument.Instrument
+		var issueType string
+		var maturity, firstSeen, lastSeen int64
+		err := rows.Scan(&inst.ISIN, &inst.Description, &inst.Coupon, &inst.CouponFrequency, &maturity,
+			&issueType, &inst.Source, &firstSeen, &lastSeen)
+		if err != nil {
+			return nil, err
+		}
+		inst.IssueType = instrument.IssueType(issueType)
+		inst.Maturity = fromMillis(maturity)
+		inst.FirstSeen = fromMillis(firstSeen)
+		inst.LastSeen = fromMillis(lastSeen)
+		results = append(results, inst)
+	}
+	return results, rows.Err()
+}
+
+func (s *Store) Instrument(isin string) (*instrument.Instrument, error) {
+	rows, err := s.db.Query(`SELECT `+instrumentColumns+` FROM instruments WHERE isin = ?`, isin)
+	if err != nil {
+		return nil, err
+	}
+	results, err := scanInstruments(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return &results[0], nil
+}
+
+func (s *Store) Instruments(isins []string) ([]instrument.Instrument, error) {
+	if len(isins) == 0 {
+		return []instrument.Instrument{}, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(isins)), ", ")
+	args := make([]any, len(isins))
+	for i, isin := range isins {
+		args[i] = isin
+	}
+	rows, err := s.db.Query(`SELECT `+instrumentColumns+` FROM instruments
+		WHERE isin IN (`+placeholders+`) ORDER BY isin`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanInstruments(rows)
+}
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/store"
+	"btpTracker/backend/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := Open(filepath.Join(t.TempDir(), "quotes.db"))
+		if err != nil {
+			t.Fatalf("Open: %s", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+func count(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %s", query, err)
+	}
+	return n
+}
+
+// A database created by a tracker that only knew the first migration is
+// upgraded on Open, keeping its quotes.
+func TestOpenMigratesVersion1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.db")
+	date := time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+
+	all := migrations
+	migrations = all[:1]
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err == nil {
+		err = migrate(db)
+	}
+	migrations = all
+	if err != nil {
+		t.Fatalf("migration 1: %s", err)
+	}
+	_, err = db.Exec(`INSERT INTO quotes (source, isin, price, insertion_date) VALUES ('btp', 'IT0005240830', 98.95, ?)`, toMillis(date))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer s.Close()
+	if v := count(t, s.db, `SELECT MAX(version) FROM schema_migrations`); v != len(migrations) {
+		t.Errorf("schema at version %d, want %d", v, len(migrations))
+	}
+
+	// The quote stored before the migration 2 has no run.
+	rows, err := s.History("btp", "IT0005240830", time.Time{}, time.Time{})
+	if err != nil || len(rows) != 1 || rows[0].Price != 98.95 || rows[0].RunID != "" || !rows[0].InsertionDate.Equal(date) {
+		t.Fatalf("History = %+v, %v; want the quote stored before the upgrade", rows, err)
+	}
+	later := date.Add(time.Hour)
+	if _, err := s.InsertSnapshot("btp", []store.Row{{ISIN: "IT0005240830", Price: 99, InsertionDate: later, RunID: "run"}}); err != nil {
+		t.Fatalf("InsertSnapshot: %s", err)
+	}
+	if latest, err := s.Latest("btp", "IT0005240830", later.Add(time.Second)); err != nil || latest.RunID != "run" {
+		t.Errorf("Latest = %+v, %v; want the row of the run", latest, err)
+	}
+
+	// The tables of the migration 3.
+	if err := s.InsertCurve(curve.Curve{Date: later, Bonds: 8}); err != nil {
+		t.Errorf("InsertCurve: %s", err)
+	}
+	rejected := []store.RejectedRow{{Source: "btp", Rejection: scraper.Rejection{Reason: "no price"}, InsertionDate: later, RunID: "run"}}
+	if _, err := s.InsertRejected(rejected); err != nil {
+		t.Errorf("InsertRejected: %s", err)
+	}
+	if err := s.InsertRun(scraper.Run{ID: "run", Source: "btp", Start: later, End: later, Pages: 2, Stored: 1}); err != nil {
+		t.Errorf("InsertRun: %s", err)
+	}
+	// The column of the migration 4.
+	if n := count(t, s.db, `SELECT pages FROM scrape_runs WHERE id = 'run'`); n != 2 {
+		t.Errorf("run stored with %d pages, want 2", n)
+	}
+	for table, want := range map[string]int{"curves": 1, "rejected_rows": 1, "scrape_runs": 1} {
+		if n := count(t, s.db, `SELECT COUNT(*) FROM `+table); n != want {
+			t.Errorf("%d rows in %s, want %d", n, table, want)
+		}
+	}
+}
+
+// Opening a migrated database applies nothing.
+func TestOpenTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.db")
+	for i := 0; i < 2; i++ {
+		s, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open %d: %s", i+1, err)
+		}
+		if n := count(t, s.db, `SELECT COUNT(*) FROM schema_migrations`); n != len(migrations) {
+			t.Errorf("%d migrations recorded, want %d", n, len(migrations))
+		}
+		s.Close()
+	}
+}
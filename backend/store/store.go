@@ -0,0 +1,59 @@
+// Package store defines where the scraped quotes and the static data of the
+// bonds are kept, so that the handlers and the scheduler do not depend on a
+// database.
+package store
+
+import (
+	"errors"
+	"time"
+
+	"btpTracker/backend/analytics"
+	"btpTracker/backend/curve"
+	"btpTracker/backend/instrument"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Row is a quote as stored by a scrape. The static data of the bond is stored
+// once per instrument.
+type Row struct {
+	ISIN              string  `json:"ISIN" bson:"ISIN"`
+	Price             float64 `json:"Price" bson:"Price"`
+	analytics.Metrics `bson:",inline"`
+	// Spread over the curve fitted to the snapshot, in basis points. Only set
+	// for the bonds the curve is meant for.
+	SpreadToCurve *float64  `json:"SpreadToCurve,omitempty" bson:"SpreadToCurve,omitempty"`
+	InsertionDate time.Time `json:"InsertionDate" bson:"InsertionDate"`
+	// ID of the scrape run that stored the row.
+	RunID string `json:"RunID,omitempty" bson:"RunID,omitempty"`
+}
+
+// QuoteStore keeps the rows of every scrape, by source (e.g. "btp"), and the
+// instruments they quote.
+type QuoteStore interface {
+	// InsertSnapshot stores the rows scraped from the source in a run. All the
+	// rows of a run share the same InsertionDate and RunID. It returns how many
+	// rows were stored, also when some of them could not be.
+	InsertSnapshot(source string, rows []Row) (int, error)
+	// History returns the rows of the ISIN stored in [from, to), oldest first.
+	// A zero from or to leaves that end of the range open.
+	History(source string, isin string, from time.Time, to time.Time) ([]Row, error)
+	// Latest returns the most recent row of the ISIN stored before `before`, or
+	// ErrNotFound.
+	Latest(source string, isin string, before time.Time) (*Row, error)
+	// LatestSnapshot returns the rows stored by the most recent run, or
+	// ErrNotFound if the source has never been scraped.
+	LatestSnapshot(source string) ([]Row, error)
+	// SpreadStats returns, for every ISIN with a spread over the curve stored
+	// since `since`, its latest spread and the statistics of all of them.
+	SpreadStats(source string, since time.Time) ([]curve.SpreadStats, error)
+
+	// UpsertInstruments refreshes the static data of the instruments quoted by
+	// a run, keeping the first time each of them was seen.
+	UpsertInstruments(insts []instrument.Instrument) error
+	// Instrument returns the instrument with the given ISIN, or ErrNotFound.
+	Instrument(isin string) (*instrument.Instrument, error)
+	// Instruments returns the instruments with the given ISINs that are known,
+	// sorted by ISIN. No ISINs (nil included) yield no instruments.
+	Instruments(isins []string) ([]instrument.Instrument, error)
+}
@@ -0,0 +1,269 @@
+// Package storetest checks that a store.Store behaves as the interface
+// documents, so that every backend is held to the same tests.
+package storetest
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"btpTracker/backend/curve"
+	"btpTracker/backend/instrument"
+	"btpTracker/backend/scraper"
+	"btpTracker/backend/store"
+)
+
+var start = time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+
+// Time of the n-th run, one a minute.
+func run(n int) time.Time {
+	return start.Add(time.Duration(n) * time.Minute)
+}
+
+func row(isin string, price float64, n int, spread *float64) store.Row {
+	return store.Row{ISIN: isin, Price: price, SpreadToCurve: spread, InsertionDate: run(n)}
+}
+
+func spread(bp float64) *float64 {
+	return &bp
+}
+
+// Prices of the rows, to compare them at a glance.
+func prices(rows []store.Row) []float64 {
+	result := make([]float64, len(rows))
+	for i, r := range rows {
+		result[i] = r.Price
+	}
+	return result
+}
+
+func equal(a []float64, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Run runs the tests of the store interface on the stores returned by open,
+// which must be empty. Each test opens its own.
+func Run(t *testing.T, open func(t *testing.T) store.Store) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s store.Store)
+	}{
+		{"History", testHistory},
+		{"Latest", testLatest},
+		{"LatestSnapshot", testLatestSnapshot},
+		{"SpreadStats", testSpreadStats},
+		{"Instruments", testInstruments},
+		{"Curves", testCurves},
+		{"Scrapes", testScrapes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, open(t))
+		})
+	}
+}
+
+// Fill the store with the runs 0 to 3 of two ISINs, inserted out of order.
+func fill(t *testing.T, s store.Store) {
+	t.Helper()
+	runs := [][]store.Row{
+		{row("A", 102, 2, spread(12)), row("B", 202, 2, nil)},
+		{row("A", 100, 0, spread(10)), row("B", 200, 0, nil)},
+		{row("A", 103, 3, spread(16)), row("B", 203, 3, nil)},
+		{row("A", 101, 1, spread(10))},
+	}
+	for _, rows := range runs {
+		n, err := s.InsertSnapshot("btp", rows)
+		if err != nil || n != len(rows) {
+			t.Fatalf("InsertSnapshot = %d, %v; want %d", n, err, len(rows))
+		}
+	}
+}
+
+func testHistory(t *testing.T, s store.Store) {
+	fill(t, s)
+	tests := []struct {
+		name     string
+		isin     string
+		from, to time.Time
+		want     []float64
+	}{
+		{"whole history, sorted", "A", time.Time{}, time.Time{}, []float64{100, 101, 102, 103}},
+		{"from is included", "A", run(1), time.Time{}, []float64{101, 102, 103}},
+		{"to is excluded", "A", time.Time{}, run(2), []float64{100, 101}},
+		{"both bounds", "B", run(1), run(3), []float64{202}},
+		{"empty range", "A", run(2), run(2), []float64{}},
+		{"unknown ISIN", "C", time.Time{}, time.Time{}, []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := s.History("btp", tt.isin, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("History: %s", err)
+			}
+			if got := prices(rows); !equal(got, tt.want) {
+				t.Errorf("History = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	rows, _ := s.History("btp", "A", time.Time{}, time.Time{})
+	if len(rows) != 4 || !rows[1].InsertionDate.Equal(run(1)) || rows[1].SpreadToCurve == nil || *rows[1].SpreadToCurve != 10 {
+		t.Errorf("History = %+v, want the date and the spread of the run 1 kept", rows)
+	}
+	rows, _ = s.History("btp", "B", time.Time{}, time.Time{})
+	if len(rows) != 3 || rows[0].SpreadToCurve != nil {
+		t.Errorf("History = %+v, want no spread for B", rows)
+	}
+}
+
+func testLatest(t *testing.T, s store.Store) {
+	fill(t, s)
+	tests := []struct {
+		isin   string
+		before time.Time
+		want   float64
+	}{
+		{"A", run(4), 103},
+		{"A", run(3), 102},
+		{"A", run(1).Add(time.Second), 101},
+		// B is not quoted in the run 1.
+		{"B", run(2), 200},
+	}
+	for _, tt := range tests {
+		got, err := s.Latest("btp", tt.isin, tt.before)
+		if err != nil {
+			t.Errorf("Latest(%s, %s): %s", tt.isin, tt.before, err)
+			continue
+		}
+		if got.Price != tt.want {
+			t.Errorf("Latest(%s, %s) = %g, want %g", tt.isin, tt.before, got.Price, tt.want)
+		}
+	}
+
+	if _, err := s.Latest("btp", "A", run(0)); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Latest before the first run: %v, want ErrNotFound", err)
+	}
+}
+
+func testLatestSnapshot(t *testing.T, s store.Store) {
+	fill(t, s)
+	rows, err := s.LatestSnapshot("btp")
+	if err != nil {
+		t.Fatalf("LatestSnapshot: %s", err)
+	}
+	if got := prices(rows); !equal(got, []float64{103, 203}) {
+		t.Errorf("LatestSnapshot = %v, want the run 3", got)
+	}
+	if _, err := s.LatestSnapshot("bot"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("LatestSnapshot of a source never scraped: %v, want ErrNotFound", err)
+	}
+}
+
+func testSpreadStats(t *testing.T, s store.Store) {
+	fill(t, s)
+	stats, err := s.SpreadStats("btp", run(1))
+	if err != nil {
+		t.Fatalf("SpreadStats: %s", err)
+	}
+	// B has no spread. The spreads of A since the run 1 are 10, 12 and 16.
+	if len(stats) != 1 {
+		t.Fatalf("SpreadStats = %+v, want A only", stats)
+	}
+	got := stats[0]
+	mean := (10.0 + 12 + 16) / 3
+	stdDev := math.Sqrt((math.Pow(10-mean, 2) + math.Pow(12-mean, 2) + math.Pow(16-mean, 2)) / 3)
+	if got.ISIN != "A" || got.Spread != 16 || !got.Date.Equal(run(3)) || got.Observations != 3 {
+		t.Errorf("SpreadStats = %+v, want the spread 16 of the run 3 and 3 observations", got)
+	}
+	if math.Abs(got.Mean-mean) > 1e-9 || math.Abs(got.StdDev-stdDev) > 1e-9 {
+		t.Errorf("mean %g and standard deviation %g, want %g and %g", got.Mean, got.StdDev, mean, stdDev)
+	}
+}
+
+func testInstruments(t *testing.T, s store.Store) {
+	maturity := run(0).AddDate(5, 0, 0)
+	s.UpsertInstruments([]instrument.Instrument{
+		{ISIN: "B", Description: "Btp-1gn27 2,2%", Coupon: 2.2, CouponFrequency: 2, Maturity: maturity, FirstSeen: run(0), LastSeen: run(0)},
+		{ISIN: "A", FirstSeen: run(0), LastSeen: run(0)},
+	})
+	s.UpsertInstruments([]instrument.Instrument{
+		{ISIN: "B", Description: "Btp-1gn27 2,2%", Coupon: 2.2, CouponFrequency: 2, Maturity: maturity, FirstSeen: run(1), LastSeen: run(1)},
+	})
+
+	got, err := s.Instrument("B")
+	if err != nil {
+		t.Fatalf("Instrument: %s", err)
+	}
+	if !got.FirstSeen.Equal(run(0)) || !got.LastSeen.Equal(run(1)) {
+		t.Errorf("seen from %s to %s, want from the run 0 to the run 1", got.FirstSeen, got.LastSeen)
+	}
+	if got.Description != "Btp-1gn27 2,2%" || got.Coupon != 2.2 || got.CouponFrequency != 2 || !got.Maturity.Equal(maturity) {
+		t.Errorf("Instrument = %+v", got)
+	}
+	if _, err := s.Instrument("C"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Instrument(C): %v, want ErrNotFound", err)
+	}
+
+	list, err := s.Instruments([]string{"C", "B", "A", "B"})
+	if err != nil {
+		t.Fatalf("Instruments: %s", err)
+	}
+	if len(list) != 2 || list[0].ISIN != "A" || list[1].ISIN != "B" {
+		t.Errorf("Instruments = %+v, want A and B", list)
+	}
+	for _, isins := range [][]string{nil, {}} {
+		if list, err := s.Instruments(isins); err != nil || list == nil || len(list) != 0 {
+			t.Errorf("Instruments(%#v) = %#v, %v; want an empty list", isins, list, err)
+		}
+	}
+}
+
+func testCurves(t *testing.T, s store.Store) {
+	for _, c := range []curve.Curve{{Date: run(2), Bonds: 9}, {Date: run(0), Bonds: 8}} {
+		if err := s.InsertCurve(c); err != nil {
+			t.Fatalf("InsertCurve: %s", err)
+		}
+	}
+	tests := []struct {
+		before time.Time
+		date   time.Time
+		bonds  int
+	}{
+		{run(1), run(0), 8},
+		{run(2), run(0), 8},
+		{run(3), run(2), 9},
+	}
+	for _, tt := range tests {
+		if c, err := s.LatestCurve(tt.before); err != nil || c.Bonds != tt.bonds || !c.Date.Equal(tt.date) {
+			t.Errorf("LatestCurve(%s) = %+v, %v; want the curve of %d bonds", tt.before, c, err, tt.bonds)
+		}
+	}
+	if _, err := s.LatestCurve(run(0)); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("LatestCurve before the first curve: %v, want ErrNotFound", err)
+	}
+}
+
+// The rejected rows and the runs are only written by the store interface: check
+// they are accepted.
+func testScrapes(t *testing.T, s store.Store) {
+	rejected := []store.RejectedRow{
+		{Source: "btp", Rejection: scraper.Rejection{Reason: "no price"}, InsertionDate: run(0), RunID: "run"},
+		{Source: "btp", Rejection: scraper.Rejection{Reason: "invalid ISIN"}, InsertionDate: run(0), RunID: "run"},
+	}
+	if n, err := s.InsertRejected(rejected); err != nil || n != 2 {
+		t.Errorf("InsertRejected = %d, %v; want 2", n, err)
+	}
+	if err := s.InsertRun(scraper.Run{ID: "run", Source: "btp", Start: run(0), End: run(1), Rows: 3, Quotes: 1, Rejected: 2, Stored: 1}); err != nil {
+		t.Errorf("InsertRun: %s", err)
+	}
+}
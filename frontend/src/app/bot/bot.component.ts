@@ -170,7 +170,7 @@ export class BotComponent implements OnInit {
     this.http.get(`http://localhost:8080/getBOTData?id=${event.data.ISIN}`).subscribe(response => {
 
       // this.data = (response as any[])
-      this.dataPlot = [{ name: event.data.ISIN, series: (response as any[]).map(({ name, value }) => ({ value: parseFloat(value.replace(",", '.')), name })) }];
+      this.dataPlot = [{ name: event.data.ISIN, series: (response as any[]).map(({ name, value }) => ({ value, name })) }];
       console.log(this.dataPlot)
       // .map(({
       //   ISIN, Description, Last, Cedola, Expiration